@@ -0,0 +1,163 @@
+// Package banner draws text in a big pixel font for the home screen.
+package banner
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed font.txt
+var fontData string
+
+const rows = 5
+
+type glyph [rows]string
+
+var font = mustParse(fontData)
+
+type Size int
+
+const (
+	None    Size = iota // too narrow, keep the plain header
+	Compact             // half height, half width
+	Full
+)
+
+// Full from 100 columns, compact from 60, nothing below that.
+func SizeFor(width int) Size {
+	switch {
+	case width >= 100:
+		return Full
+	case width >= 60:
+		return Compact
+	}
+	return None
+}
+
+type key struct {
+	text string
+	size Size
+}
+
+var (
+	cache   = map[key]string{}
+	cacheMu sync.Mutex
+)
+
+// text drawn at size s, "" for None. Each text and size is only drawn
+// once.
+func Render(text string, s Size) string {
+	if s == None {
+		return ""
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	k := key{text, s}
+	if b, ok := cache[k]; ok {
+		return b
+	}
+	b := draw(text, s)
+	cache[k] = b
+	return b
+}
+
+func draw(text string, s Size) string {
+	var glyphs []glyph
+	for _, r := range text {
+		g, ok := font[unicode.ToLower(r)]
+		if !ok {
+			g = font[' ']
+		}
+		glyphs = append(glyphs, g)
+	}
+
+	var lines []string
+	if s == Full {
+		// Two cells per pixel so it comes out roughly square.
+		for y := range rows {
+			var b strings.Builder
+			for i, g := range glyphs {
+				if i > 0 {
+					b.WriteString("  ")
+				}
+				for _, px := range g[y] {
+					b.WriteString(pick(px == '#', "██", "  "))
+				}
+			}
+			lines = append(lines, b.String())
+		}
+	} else {
+		// Pairs of pixel rows share a line through half blocks.
+		for y := 0; y < rows; y += 2 {
+			var b strings.Builder
+			for i, g := range glyphs {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				for x, px := range g[y] {
+					top := px == '#'
+					bottom := y+1 < rows && g[y+1][x] == '#'
+					switch {
+					case top && bottom:
+						b.WriteString("█")
+					case top:
+						b.WriteString("▀")
+					case bottom:
+						b.WriteString("▄")
+					default:
+						b.WriteString(" ")
+					}
+				}
+			}
+			lines = append(lines, b.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pick(on bool, a, b string) string {
+	if on {
+		return a
+	}
+	return b
+}
+
+// font.txt starts with "# " comments, then every glyph is its character
+// on a line followed by its rows, all of one width.
+func parse(data string) (map[rune]glyph, error) {
+	lines := strings.Split(strings.TrimRight(data, "\n"), "\n")
+	n := 0
+	for n < len(lines) && (lines[n] == "" || strings.HasPrefix(lines[n], "# ")) {
+		n++
+	}
+
+	glyphs := map[rune]glyph{}
+	for n < len(lines) {
+		if utf8.RuneCountInString(lines[n]) != 1 || n+rows >= len(lines) {
+			return nil, fmt.Errorf("line %d: expected a character followed by %d rows", n+1, rows)
+		}
+		r, _ := utf8.DecodeRuneInString(lines[n])
+		var g glyph
+		for y := range rows {
+			g[y] = lines[n+1+y]
+			if len(g[y]) != len(g[0]) {
+				return nil, fmt.Errorf("line %d: rows of %q have different widths", n+2+y, r)
+			}
+		}
+		glyphs[r] = g
+		n += rows + 1
+	}
+	return glyphs, nil
+}
+
+func mustParse(data string) map[rune]glyph {
+	glyphs, err := parse(data)
+	if err != nil {
+		panic("banner: bad font.txt: " + err.Error())
+	}
+	return glyphs
+}
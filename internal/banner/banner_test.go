@@ -0,0 +1,87 @@
+package banner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSizeFor(t *testing.T) {
+	for _, tt := range []struct {
+		width int
+		want  Size
+	}{
+		{0, None}, {40, None}, {59, None},
+		{60, Compact}, {80, Compact}, {99, Compact},
+		{100, Full}, {200, Full},
+	} {
+		if got := SizeFor(tt.width); got != tt.want {
+			t.Errorf("SizeFor(%d) = %v, want %v", tt.width, got, tt.want)
+		}
+	}
+}
+
+// The site name drawn at each size fits the narrowest terminal that
+// gets that size.
+func TestRenderFitsItsWidth(t *testing.T) {
+	for _, tt := range []struct {
+		width  int
+		height int
+	}{
+		{60, 3},  // compact, two pixel rows a line
+		{100, 5}, // full
+	} {
+		b := Render("willx86.com", SizeFor(tt.width))
+		if w := lipgloss.Width(b); w == 0 || w > tt.width {
+			t.Errorf("at %d columns the banner is %d wide", tt.width, w)
+		}
+		if h := lipgloss.Height(b); h != tt.height {
+			t.Errorf("at %d columns the banner is %d lines, want %d", tt.width, h, tt.height)
+		}
+	}
+	if b := Render("willx86.com", SizeFor(59)); b != "" {
+		t.Errorf("at 59 columns got a banner:\n%s", b)
+	}
+}
+
+func TestRenderGlyphs(t *testing.T) {
+	// Two cells per pixel, straight from font.txt.
+	full := strings.Split(Render("A", Full), "\n")
+	for y, row := range font['a'] {
+		want := strings.NewReplacer("#", "██", ".", "  ").Replace(row)
+		if full[y] != want {
+			t.Errorf("row %d = %q, want %q", y, full[y], want)
+		}
+	}
+	if Render("a", Compact) != Render("A", Compact) {
+		t.Error("upper and lower case drawn differently")
+	}
+
+	// Characters the font lacks are gaps the width of a space.
+	if got, want := Render("a~a", Full), Render("a a", Full); got != want {
+		t.Errorf("unknown rune drawn as\n%s\nwant\n%s", got, want)
+	}
+
+	// Compact halves the rows with half blocks.
+	for _, line := range strings.Split(Render("willx86.com", Compact), "\n") {
+		if strings.Trim(line, "█▀▄ ") != "" {
+			t.Errorf("compact line %q has more than half blocks", line)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"short glyph":  "# font\na\n###\n#.#\n",
+		"ragged rows":  "a\n###\n#.#\n##\n#.#\n#.#\n",
+		"not one rune": "ab\n###\n#.#\n###\n#.#\n#.#\n",
+	} {
+		if _, err := parse(data); err == nil {
+			t.Errorf("%s: parsed", name)
+		}
+	}
+	if _, err := parse(fontData); err != nil {
+		t.Errorf("font.txt: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Package diagnostics builds the operator bundle: config, logs, metrics,
+// profiles and store state in one tar.gz, with secrets and visitor PII
+// redacted.
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/metrics"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+	"github.com/will-x86/ssh-will-x86/internal/web"
+)
+
+const (
+	maxFileSize   = 8 << 20
+	maxBundleSize = 32 << 20
+)
+
+var errBundleFull = errors.New("diagnostics: bundle size cap reached")
+
+type Sources struct {
+	Flags    *flag.FlagSet
+	Logs     *Ring
+	Registry *metrics.Registry
+	Metrics  *metrics.Metrics
+}
+
+// Writes the gzipped tar bundle to w.
+func Write(w io.Writer, src Sources) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	b := &bundle{tw: tw, now: time.Now()}
+
+	b.add("config.json", src.config)
+	b.add("logs.txt", src.logs)
+	b.add("metrics.txt", func(w io.Writer) error { src.Registry.Write(w); return nil })
+	b.add("goroutines.txt", func(w io.Writer) error { return pprof.Lookup("goroutine").WriteTo(w, 2) })
+	b.add("heap.pprof", func(w io.Writer) error { return pprof.Lookup("heap").WriteTo(w, 0) })
+	b.add("runtime.json", runtimeInfo)
+	b.add("content.txt", contentDiagnostics)
+	b.add("store.json", func(w io.Writer) error { return writeJSON(w, store.QueueStats()) })
+	b.add("sessions.json", src.sessions)
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if b.err != nil && !errors.Is(b.err, errBundleFull) {
+		return b.err
+	}
+	return nil
+}
+
+// Admin endpoint streaming the bundle, gated on the webserver secret.
+func Handler(src Sources) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !web.Authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="diagnostics.tar.gz"`)
+		if err := Write(w, src); err != nil {
+			log.Errorf("Diagnostics: writing bundle failed: %v", err)
+		}
+	}
+}
+
+type bundle struct {
+	tw    *tar.Writer
+	now   time.Time
+	total int
+	err   error
+}
+
+// Renders one file into memory so its size is known for the tar
+// header, each file and the whole bundle are capped.
+func (b *bundle) add(name string, fill func(io.Writer) error) {
+	if b.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	lw := &limitWriter{w: &buf, n: min(maxFileSize, maxBundleSize-b.total)}
+	if err := fill(lw); err != nil && !errors.Is(err, errBundleFull) {
+		fmt.Fprintf(&buf, "\n[error collecting %s: %v]\n", name, err)
+	}
+	if lw.truncated {
+		buf.WriteString("\n[truncated]\n")
+	}
+
+	if err := b.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(buf.Len()),
+		ModTime: b.now,
+	}); err != nil {
+		b.err = err
+		return
+	}
+	if _, err := b.tw.Write(buf.Bytes()); err != nil {
+		b.err = err
+		return
+	}
+	b.total += buf.Len()
+	if b.total >= maxBundleSize {
+		b.err = errBundleFull
+	}
+}
+
+type limitWriter struct {
+	w         io.Writer
+	n         int
+	truncated bool
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > l.n {
+		l.truncated = true
+		_, _ = l.w.Write(p[:max(l.n, 0)])
+		l.n = 0
+		return len(p), errBundleFull
+	}
+	l.n -= len(p)
+	return l.w.Write(p)
+}
+
+func (s Sources) config(w io.Writer) error {
+	cfg := map[string]string{}
+	if s.Flags != nil {
+		s.Flags.VisitAll(func(f *flag.Flag) {
+			cfg[f.Name] = RedactConfig(f.Name, f.Value.String())
+		})
+	}
+	return writeJSON(w, cfg)
+}
+
+func (s Sources) logs(w io.Writer) error {
+	if s.Logs == nil {
+		return nil
+	}
+	for _, line := range s.Logs.Lines() {
+		if _, err := io.WriteString(w, RedactLogLine(line)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Sources) sessions(w io.Writer) error {
+	if s.Metrics == nil {
+		return nil
+	}
+	return writeJSON(w, map[string]float64{
+		"active": s.Metrics.SessionsActive.Value(),
+		"total":  s.Metrics.SessionsTotal.Value(),
+	})
+}
+
+func runtimeInfo(w io.Writer) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return writeJSON(w, map[string]any{
+		"go_version":   runtime.Version(),
+		"goroutines":   runtime.NumGoroutine(),
+		"heap_alloc":   mem.HeapAlloc,
+		"heap_inuse":   mem.HeapInuse,
+		"num_gc":       mem.NumGC,
+		"collected_at": time.Now().Format(time.RFC3339),
+	})
+}
+
+func contentDiagnostics(w io.Writer) error {
+	projects, err := store.LoadProjects()
+	if err != nil {
+		fmt.Fprintf(w, "projects.txt: %v\n", err)
+		return nil
+	}
+	fmt.Fprintf(w, "projects.txt: %d projects\n", len(projects))
+	seen := map[int]bool{}
+	for _, p := range projects {
+		dup := ""
+		if seen[p.ProjectNumber] {
+			dup = " (duplicate number)"
+		}
+		seen[p.ProjectNumber] = true
+		fmt.Fprintf(w, "  #%d %q url=%q date=%s tags=%v%s\n", p.ProjectNumber, p.ProjectTitle, p.ProjectURL, p.ProjectDate.Format(store.DateLayout), p.ProjectTags, dup)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Downloads the bundle from a running server at base into path.
+func Fetch(base, secret, path string) error {
+	req, err := http.NewRequest(http.MethodGet, base+"/diagnostics", nil) //nolint:noctx
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+	// Only ever pointed at our own loopback listener, whose cert won't
+	// be issued for 127.0.0.1.
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}} //nolint:gosec
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("diagnostics: server returned %d", resp.StatusCode)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, io.LimitReader(resp.Body, maxBundleSize+1<<20)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,101 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// Flags/config keys holding credentials, webhook and Worker URLs embed
+// tokens.
+var secretNames = regexp.MustCompile(`(?i)secret|key|salt|token|password|^sk$|notify-url|workerurl`)
+
+// Structured log keys carrying visitor PII or message bodies.
+var piiKeys = []string{"content", "from", "user", "remote", "ip", "secret", "body"}
+
+// Unstructured log lines that embed a message body after a prefix.
+var piiPrefixes = []string{
+	"Printing message ",
+	"Got message from worker: ",
+	"Message too long: ",
+}
+
+var logfmtPair = regexp.MustCompile(`\b(` + strings.Join(piiKeys, "|") + `)=("(?:[^"\\]|\\.)*"|\S+)`)
+
+var secretParam = regexp.MustCompile(`(?i)([?&](?:secret|token|key)=)[^&\s"']*`)
+
+// Config value safe to print, secrets are blanked and URLs lose their
+// credentials.
+func RedactConfig(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretNames.MatchString(name) {
+		return redacted
+	}
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		if u.User != nil {
+			u.User = url.User(redacted)
+		}
+		return RedactText(u.String())
+	}
+	return value
+}
+
+// Strips message bodies, visitor identifiers and secrets from a log line,
+// in any of the -log-format formats.
+func RedactLogLine(line string) string {
+	if strings.HasPrefix(line, "{") {
+		if out, ok := redactJSON(line); ok {
+			return out
+		}
+	}
+	line = redactPrefixes(line)
+	line = logfmtPair.ReplaceAllString(line, "$1="+redacted)
+	return RedactText(line)
+}
+
+func redactPrefixes(line string) string {
+	for _, prefix := range piiPrefixes {
+		if i := strings.Index(line, prefix); i >= 0 {
+			line = line[:i+len(prefix)] + redacted
+		}
+	}
+	return line
+}
+
+// A JSON log line redacted by key, false if it isn't a JSON object.
+func redactJSON(line string) (string, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return "", false
+	}
+	for k, v := range fields {
+		s, isString := v.(string)
+		switch {
+		case slices.Contains(piiKeys, k):
+			fields[k] = redacted
+		case k == "msg" && isString:
+			fields[k] = RedactText(redactPrefixes(s))
+		case isString:
+			fields[k] = RedactText(s)
+		}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(fields); err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
+
+// Blanks secret query parameters anywhere in s.
+func RedactText(s string) string {
+	return secretParam.ReplaceAllString(s, "${1}"+redacted)
+}
@@ -0,0 +1,57 @@
+package diagnostics
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Ring keeps the last n log lines, tee the logger into it.
+type Ring struct {
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+	part  []byte
+}
+
+func NewRing(n int) *Ring {
+	return &Ring{lines: make([][]byte, n)}
+}
+
+func (r *Ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.part, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		r.lines[r.next] = append([]byte(nil), data[:i]...)
+		r.next = (r.next + 1) % len(r.lines)
+		if r.next == 0 {
+			r.full = true
+		}
+		data = data[i+1:]
+	}
+	r.part = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Lines oldest first.
+func (r *Ring) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []string
+	if r.full {
+		for _, l := range r.lines[r.next:] {
+			out = append(out, string(l))
+		}
+	}
+	for _, l := range r.lines[:r.next] {
+		out = append(out, string(l))
+	}
+	return out
+}
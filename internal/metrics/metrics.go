@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// Everything the site exports, registered on a single Registry.
+type Metrics struct {
+	SessionsActive    *Gauge
+	SessionsTotal     *Counter
+	SessionDuration   *Histogram
+	MessagesSubmitted *Counter
+	MessagesFetched   *CounterVec
+	HTTPRequests      *CounterVec
+	AuthFailures      *CounterVec
+}
+
+func New(r *Registry) *Metrics {
+	return &Metrics{
+		SessionsActive:    r.Gauge("ssh_sessions_active", "Currently connected SSH sessions."),
+		SessionsTotal:     r.Counter("ssh_sessions_total", "SSH sessions since start."),
+		SessionDuration:   r.Histogram("ssh_session_duration_seconds", "SSH session length.", []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}),
+		MessagesSubmitted: r.Counter("messages_submitted_total", "Messages left by visitors."),
+		MessagesFetched:   r.CounterVec("messages_fetched_total", "Messages handed to the printer.", "source"),
+		HTTPRequests:      r.CounterVec("http_requests_total", "HTTP requests served.", "path", "code"),
+		AuthFailures:      r.CounterVec("ssh_auth_failures_total", "Denied SSH auth attempts.", "method"),
+	}
+}
+
+// Wraps the wish handler chain to track sessions.
+func (m *Metrics) Middleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			start := time.Now()
+			m.SessionsTotal.Inc()
+			m.SessionsActive.Inc()
+			defer func() {
+				m.SessionsActive.Dec()
+				m.SessionDuration.Observe(time.Since(start).Seconds())
+			}()
+			next(s)
+		}
+	}
+}
+
+// Counts requests to h by path and status code.
+func (m *Metrics) Instrument(path string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		h(rec, r)
+		m.HTTPRequests.Inc(path, strconv.Itoa(rec.code))
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
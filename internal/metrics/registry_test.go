@@ -0,0 +1,243 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type sample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+type family struct {
+	typ, help string
+	samples   []sample
+}
+
+// Parses the text exposition format strictly enough to catch anything a
+// Prometheus scrape would reject: every sample under a TYPE of its own
+// family, well formed labels and values.
+func parseExposition(t *testing.T, r io.Reader) map[string]*family {
+	t.Helper()
+	families := map[string]*family{}
+	var current string
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if rest, ok := strings.CutPrefix(line, "# "); ok {
+			kind, rest, _ := strings.Cut(rest, " ")
+			name, text, _ := strings.Cut(rest, " ")
+			switch kind {
+			case "HELP":
+				if families[name] != nil {
+					t.Fatalf("line %d: %s described twice", n, name)
+				}
+				families[name] = &family{help: text}
+			case "TYPE":
+				f := families[name]
+				if f == nil || f.typ != "" {
+					t.Fatalf("line %d: TYPE for %s without a HELP of its own", n, name)
+				}
+				switch text {
+				case "counter", "gauge", "histogram":
+				default:
+					t.Fatalf("line %d: unknown type %q", n, text)
+				}
+				f.typ = text
+				current = name
+			default:
+				t.Fatalf("line %d: unknown comment %q", n, line)
+			}
+			continue
+		}
+
+		s := parseSample(t, n, line)
+		f := families[current]
+		base := s.name
+		if f != nil && f.typ == "histogram" {
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				if b, ok := strings.CutSuffix(s.name, suffix); ok {
+					base = b
+					break
+				}
+			}
+		}
+		if f == nil || base != current {
+			t.Fatalf("line %d: sample %s outside its family", n, s.name)
+		}
+		f.samples = append(f.samples, s)
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return families
+}
+
+func parseSample(t *testing.T, n int, line string) sample {
+	t.Helper()
+	s := sample{labels: map[string]string{}}
+	i := strings.IndexAny(line, "{ ")
+	if i <= 0 {
+		t.Fatalf("line %d: no metric name in %q", n, line)
+	}
+	s.name, line = line[:i], line[i:]
+	if rest, ok := strings.CutPrefix(line, "{"); ok {
+		for !strings.HasPrefix(rest, "}") {
+			name, after, ok := strings.Cut(rest, `="`)
+			if !ok || name == "" {
+				t.Fatalf("line %d: bad label in %q", n, rest)
+			}
+			var v strings.Builder
+			j := 0
+			for ; j < len(after) && after[j] != '"'; j++ {
+				if after[j] == '\\' {
+					j++
+					switch {
+					case j >= len(after):
+					case after[j] == 'n':
+						v.WriteByte('\n')
+						continue
+					case after[j] == '\\' || after[j] == '"':
+						v.WriteByte(after[j])
+						continue
+					}
+					t.Fatalf("line %d: bad escape in %q", n, after)
+				}
+				v.WriteByte(after[j])
+			}
+			if j == len(after) {
+				t.Fatalf("line %d: unterminated label %s", n, name)
+			}
+			s.labels[name] = v.String()
+			rest = strings.TrimPrefix(after[j+1:], ",")
+		}
+		line = rest[1:]
+	}
+	value, ok := strings.CutPrefix(line, " ")
+	if !ok {
+		t.Fatalf("line %d: no value", n)
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		t.Fatalf("line %d: value %q: %v", n, value, err)
+	}
+	s.value = v
+	return s
+}
+
+func scrape(t *testing.T, r *Registry) map[string]*family {
+	t.Helper()
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("scrape: %s, %q", resp.Status, resp.Header.Get("Content-Type"))
+	}
+	return parseExposition(t, resp.Body)
+}
+
+func TestScrape(t *testing.T) {
+	r := NewRegistry()
+	m := New(r)
+	m.SessionsTotal.Inc()
+	m.SessionsTotal.Inc()
+	m.SessionsActive.Inc()
+	m.SessionDuration.Observe(3)
+	m.SessionDuration.Observe(45)
+	m.SessionDuration.Observe(7200)
+	m.MessagesFetched.Inc("memory")
+	m.AuthFailures.Inc("quiz")
+
+	handler := m.Instrument("/messages", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/messages", nil))
+	// Label values a scraper has to be able to read back.
+	m.HTTPRequests.Inc("/a \"quoted\"\\path\tand\nnext", "200")
+	m.HTTPRequests.Inc("/é", "200")
+
+	families := scrape(t, r)
+	for name, typ := range map[string]string{
+		"ssh_sessions_active":          "gauge",
+		"ssh_sessions_total":           "counter",
+		"ssh_session_duration_seconds": "histogram",
+		"messages_submitted_total":     "counter",
+		"messages_fetched_total":       "counter",
+		"http_requests_total":          "counter",
+		"ssh_auth_failures_total":      "counter",
+	} {
+		if f := families[name]; f == nil || f.typ != typ || f.help == "" {
+			t.Errorf("%s: got %+v, want a described %s", name, f, typ)
+		}
+	}
+
+	value := func(name string, labels map[string]string) float64 {
+		t.Helper()
+		for _, f := range families {
+		samples:
+			for _, s := range f.samples {
+				if s.name != name || len(s.labels) != len(labels) {
+					continue
+				}
+				for k, v := range labels {
+					if s.labels[k] != v {
+						continue samples
+					}
+				}
+				return s.value
+			}
+		}
+		t.Errorf("no sample %s%v", name, labels)
+		return 0
+	}
+	if v := value("ssh_sessions_total", nil); v != 2 {
+		t.Errorf("ssh_sessions_total = %v, want 2", v)
+	}
+	if v := value("ssh_sessions_active", nil); v != 1 {
+		t.Errorf("ssh_sessions_active = %v, want 1", v)
+	}
+	if v := value("messages_fetched_total", map[string]string{"source": "memory"}); v != 1 {
+		t.Errorf("messages_fetched_total = %v, want 1", v)
+	}
+	if v := value("http_requests_total", map[string]string{"path": "/messages", "code": "418"}); v != 1 {
+		t.Errorf("instrumented request = %v, want 1", v)
+	}
+	value("http_requests_total", map[string]string{"path": "/a \"quoted\"\\path\tand\nnext", "code": "200"})
+	value("http_requests_total", map[string]string{"path": "/é", "code": "200"})
+
+	// Buckets are cumulative and end at +Inf, which is the count.
+	prev := 0.0
+	for _, s := range families["ssh_session_duration_seconds"].samples {
+		if s.name != "ssh_session_duration_seconds_bucket" {
+			continue
+		}
+		if s.value < prev {
+			t.Errorf("bucket le=%s = %v, below the one before", s.labels["le"], s.value)
+		}
+		prev = s.value
+	}
+	if v := value("ssh_session_duration_seconds_bucket", map[string]string{"le": "5"}); v != 1 {
+		t.Errorf("le=5 bucket = %v, want 1", v)
+	}
+	if v := value("ssh_session_duration_seconds_bucket", map[string]string{"le": "60"}); v != 2 {
+		t.Errorf("le=60 bucket = %v, want 2", v)
+	}
+	inf := value("ssh_session_duration_seconds_bucket", map[string]string{"le": "+Inf"})
+	if count := value("ssh_session_duration_seconds_count", nil); inf != 3 || count != 3 {
+		t.Errorf("+Inf bucket %v, count %v, want 3", inf, count)
+	}
+	if sum := value("ssh_session_duration_seconds_sum", nil); sum != 7248 {
+		t.Errorf("sum = %v, want 7248", sum)
+	}
+}
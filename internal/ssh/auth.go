@@ -0,0 +1,125 @@
+package ssh
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Outcome of a single auth step.
+type Decision int
+
+const (
+	Continue Decision = iota // no opinion, ask the next step
+	Allow                    // accept the connection
+	Deny                     // reject the connection
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	default:
+		return "continue"
+	}
+}
+
+// What a step gets to look at for one auth attempt.
+// PublicKey is only set for public key auth, Challenger only for
+// keyboard-interactive.
+type AuthAttempt struct {
+	Method     string
+	Ctx        ssh.Context
+	PublicKey  ssh.PublicKey
+	Challenger gossh.KeyboardInteractiveChallenge
+}
+
+// A named policy step, returns its decision and why.
+type AuthStep struct {
+	Name  string
+	Check func(a AuthAttempt) (Decision, string)
+}
+
+// Steps evaluated in order, the first Allow or Deny wins.
+// If every step continues the attempt is denied. Observers see every
+// final decision, e.g. to count failures.
+type AuthChain struct {
+	Steps     []AuthStep
+	Observers []func(a AuthAttempt, d Decision)
+}
+
+func (c AuthChain) Evaluate(a AuthAttempt) bool {
+	step, decision, reason := "", Deny, "no step allowed the attempt"
+	for _, s := range c.Steps {
+		d, r := s.Check(a)
+		if d == Continue {
+			continue
+		}
+		step, decision, reason = s.Name, d, r
+		break
+	}
+
+	log.Info("Auth decision",
+		"method", a.Method,
+		"user", a.Ctx.User(),
+		"remote", a.Ctx.RemoteAddr().String(),
+		"client", a.Ctx.ClientVersion(),
+		"step", step,
+		"decision", decision,
+		"reason", reason,
+	)
+	for _, observe := range c.Observers {
+		observe(a, decision)
+	}
+	return decision == Allow
+}
+
+func (c AuthChain) publicKey(ctx ssh.Context, key ssh.PublicKey) bool {
+	return c.Evaluate(AuthAttempt{Method: "publickey", Ctx: ctx, PublicKey: key})
+}
+
+func (c AuthChain) keyboardInteractive(ctx ssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+	return c.Evaluate(AuthAttempt{Method: "keyboard-interactive", Ctx: ctx, Challenger: challenger})
+}
+
+// The chain the server runs with, compose new policies here.
+func defaultAuthChain(trusted []ssh.PublicKey, authorized []AuthorizedKey, quiz quizConfig, noAuth bool, gate *Gate) AuthChain {
+	login := AuthStep{Name: "ide-quiz", Check: quiz.step}
+	if noAuth {
+		login = AuthStep{Name: "no-auth", Check: noAuthStep}
+	}
+	return AuthChain{
+		Steps: []AuthStep{
+			{Name: "ip-ban", Check: gate.banStep},
+			{Name: "trusted-key", Check: trustedKeyStep(trusted)},
+			{Name: "authorized-key", Check: authorizedKeyStep(authorized)},
+			login,
+		},
+		Observers: []func(AuthAttempt, Decision){gate.observe},
+	}
+}
+
+// Demo mode, keyboard-interactive lets everyone in without asking.
+func noAuthStep(a AuthAttempt) (Decision, string) {
+	if a.Challenger == nil {
+		return Continue, "not keyboard-interactive"
+	}
+	return Allow, "auth disabled"
+}
+
+// In-process keys (e.g. the web terminal bridge) skip the quiz.
+func trustedKeyStep(trusted []ssh.PublicKey) func(a AuthAttempt) (Decision, string) {
+	return func(a AuthAttempt) (Decision, string) {
+		if a.PublicKey == nil {
+			return Continue, "no public key"
+		}
+		for _, k := range trusted {
+			if ssh.KeysEqual(a.PublicKey, k) {
+				return Allow, "trusted key"
+			}
+		}
+		return Continue, "untrusted key"
+	}
+}
@@ -0,0 +1,296 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Enough of ssh.Context for the auth steps.
+type fakeCtx struct {
+	context.Context
+	sync.Mutex
+	remote net.Addr
+	values map[any]any
+}
+
+func newFakeCtx(remote string) *fakeCtx {
+	addr, err := net.ResolveTCPAddr("tcp", remote)
+	if err != nil {
+		panic(err)
+	}
+	return &fakeCtx{Context: context.Background(), remote: addr, values: map[any]any{}}
+}
+
+func (c *fakeCtx) User() string                  { return "visitor" }
+func (c *fakeCtx) SessionID() string             { return "session" }
+func (c *fakeCtx) ClientVersion() string         { return "SSH-2.0-test" }
+func (c *fakeCtx) ServerVersion() string         { return "SSH-2.0-server" }
+func (c *fakeCtx) RemoteAddr() net.Addr          { return c.remote }
+func (c *fakeCtx) LocalAddr() net.Addr           { return c.remote }
+func (c *fakeCtx) Permissions() *ssh.Permissions { return &ssh.Permissions{} }
+func (c *fakeCtx) SetValue(k, v any)             { c.values[k] = v }
+
+func (c *fakeCtx) Value(k any) any {
+	if v, ok := c.values[k]; ok {
+		return v
+	}
+	return c.Context.Value(k)
+}
+
+func newKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := gossh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func fixed(d Decision) func(AuthAttempt) (Decision, string) {
+	return func(AuthAttempt) (Decision, string) { return d, d.String() }
+}
+
+func TestEvaluate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		steps []Decision
+		want  bool
+		ran   int // steps checked before the decision
+	}{
+		{"first allow wins", []Decision{Allow, Deny}, true, 1},
+		{"first deny wins", []Decision{Deny, Allow}, false, 1},
+		{"continue falls through", []Decision{Continue, Allow}, true, 2},
+		{"all continue denies", []Decision{Continue, Continue}, false, 2},
+		{"no steps denies", nil, false, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := 0
+			var chain AuthChain
+			for _, d := range tt.steps {
+				check := fixed(d)
+				chain.Steps = append(chain.Steps, AuthStep{Name: d.String(), Check: func(a AuthAttempt) (Decision, string) {
+					ran++
+					return check(a)
+				}})
+			}
+			var observed []Decision
+			chain.Observers = []func(AuthAttempt, Decision){
+				func(_ AuthAttempt, d Decision) { observed = append(observed, d) },
+				func(_ AuthAttempt, d Decision) { observed = append(observed, d) },
+			}
+
+			got := chain.Evaluate(AuthAttempt{Method: "publickey", Ctx: newFakeCtx("192.0.2.1:22")})
+			if got != tt.want {
+				t.Errorf("Evaluate = %v, want %v", got, tt.want)
+			}
+			if ran != tt.ran {
+				t.Errorf("%d steps ran, want %d", ran, tt.ran)
+			}
+			want := Deny
+			if tt.want {
+				want = Allow
+			}
+			if len(observed) != 2 || observed[0] != want || observed[1] != want {
+				t.Errorf("observers saw %v, want %v twice", observed, want)
+			}
+		})
+	}
+}
+
+func TestBanStep(t *testing.T) {
+	g := NewGate(GateConfig{MaxFailures: 1, FailureWindow: time.Minute, BanDuration: time.Hour})
+	a := AuthAttempt{Ctx: newFakeCtx("192.0.2.1:22")}
+	if d, _ := g.banStep(a); d != Continue {
+		t.Fatalf("before a failure: %v, want continue", d)
+	}
+	g.Fail("192.0.2.1")
+	if d, _ := g.banStep(a); d != Deny {
+		t.Fatalf("banned IP: %v, want deny", d)
+	}
+	if d, _ := g.banStep(AuthAttempt{Ctx: newFakeCtx("192.0.2.2:22")}); d != Continue {
+		t.Fatalf("other IP: %v, want continue", d)
+	}
+}
+
+func TestKeySteps(t *testing.T) {
+	known, other := newKey(t), newKey(t)
+
+	trusted := trustedKeyStep([]ssh.PublicKey{known})
+	ctx := newFakeCtx("192.0.2.1:22")
+	for _, tt := range []struct {
+		key  ssh.PublicKey
+		want Decision
+	}{{known, Allow}, {other, Continue}, {nil, Continue}} {
+		if d, _ := trusted(AuthAttempt{Ctx: ctx, PublicKey: tt.key}); d != tt.want {
+			t.Errorf("trustedKeyStep: %v, want %v", d, tt.want)
+		}
+	}
+
+	authorized := authorizedKeyStep([]AuthorizedKey{{Key: known, Comment: "will@laptop"}})
+	if d, _ := authorized(AuthAttempt{Ctx: ctx, PublicKey: other}); d != Continue {
+		t.Errorf("authorizedKeyStep with an unknown key: %v, want continue", d)
+	}
+	if _, ok := AuthorizedKeyFrom(ctx); ok {
+		t.Error("unknown key was recorded on the context")
+	}
+	if d, _ := authorized(AuthAttempt{Ctx: ctx, PublicKey: known}); d != Allow {
+		t.Errorf("authorizedKeyStep with a listed key: %v, want allow", d)
+	}
+	if _, ok := AuthorizedKeyFrom(ctx); ok {
+		t.Error("listed key recorded before the session verified it")
+	}
+}
+
+// A client can ask whether someone else's public key would do without
+// holding its private key (ssh -i victim.pub), get a yes, then log in
+// through the quiz. The session mustn't carry the key it never signed
+// with.
+func TestAuthorizedKeyQueryThenQuiz(t *testing.T) {
+	victim := newKey(t)
+	keys := []AuthorizedKey{{Key: victim, Comment: "alice@victim"}}
+	chain := defaultAuthChain(nil, keys, quizConfig{pool: []Quiz{DefaultQuiz}}, false, NewGate(GateConfig{}))
+
+	// The same callback answers the query and the signed attempt.
+	s := newFakeSession()
+	if !chain.publicKey(s.ctx, victim) {
+		t.Fatal("query for the listed key refused")
+	}
+	if !chain.keyboardInteractive(s.ctx, (&fakeChallenger{answers: [][]string{{"vim"}}}).challenge) {
+		t.Fatal("quiz refused")
+	}
+
+	var got AuthorizedKey
+	var found bool
+	authorizedKeyMiddleware(keys)(func(s ssh.Session) {
+		got, found = AuthorizedKeyFrom(s.Context())
+	})(s)
+	if found {
+		t.Errorf("session logged in by quiz carries %q", got.Comment)
+	}
+}
+
+func TestAuthorizedKeyMiddleware(t *testing.T) {
+	listed, other := newKey(t), newKey(t)
+	keys := []AuthorizedKey{{Key: listed, Comment: "will@laptop"}}
+	for _, tt := range []struct {
+		name string
+		key  ssh.PublicKey
+		want string
+	}{{"listed key", listed, "will@laptop"}, {"other key", other, ""}, {"no key", nil, ""}} {
+		s := newFakeSession()
+		s.pub = tt.key
+		var got AuthorizedKey
+		authorizedKeyMiddleware(keys)(func(s ssh.Session) {
+			got, _ = AuthorizedKeyFrom(s.Context())
+		})(s)
+		if got.Comment != tt.want {
+			t.Errorf("%s: AuthorizedKeyFrom = %q, want %q", tt.name, got.Comment, tt.want)
+		}
+	}
+}
+
+func TestNoAuthStep(t *testing.T) {
+	if d, _ := noAuthStep(AuthAttempt{}); d != Continue {
+		t.Errorf("public key attempt: %v, want continue", d)
+	}
+	challenger := func(string, string, []string, []bool) ([]string, error) { return nil, nil }
+	if d, _ := noAuthStep(AuthAttempt{Challenger: challenger}); d != Allow {
+		t.Errorf("keyboard-interactive: %v, want allow", d)
+	}
+}
+
+// A challenger answering from answers in turn, recording the hints.
+type fakeChallenger struct {
+	answers [][]string
+	err     error
+	hints   []string
+}
+
+func (f *fakeChallenger) challenge(_, hint string, questions []string, _ []bool) ([]string, error) {
+	f.hints = append(f.hints, hint)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if len(f.answers) == 0 {
+		return make([]string, len(questions)), nil
+	}
+	a := f.answers[0]
+	f.answers = f.answers[1:]
+	return a, nil
+}
+
+func TestQuizStep(t *testing.T) {
+	pool := []Quiz{DefaultQuiz}
+	for _, tt := range []struct {
+		name    string
+		retries int
+		answers [][]string
+		err     error
+		want    Decision
+		asked   int
+	}{
+		{"right first time", 2, [][]string{{"vim"}}, nil, Allow, 1},
+		{"case and spaces ignored", 0, [][]string{{" VIM "}}, nil, Allow, 1},
+		{"right on a retry", 2, [][]string{{"emacs"}, {"vim"}}, nil, Allow, 2},
+		{"out of retries", 2, [][]string{{"emacs"}, {"nano"}, {"code"}, {"vim"}}, nil, Deny, 3},
+		{"no retries", 0, [][]string{{"emacs"}, {"vim"}}, nil, Deny, 1},
+		{"wrong number of answers", 1, [][]string{{"vim", "vim"}, {}}, nil, Deny, 2},
+		{"challenge error", 2, nil, errors.New("client went away"), Deny, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeChallenger{answers: tt.answers, err: tt.err}
+			q := quizConfig{pool: pool, retries: tt.retries}
+			d, _ := q.step(AuthAttempt{Challenger: f.challenge})
+			if d != tt.want {
+				t.Errorf("step = %v, want %v", d, tt.want)
+			}
+			if len(f.hints) != tt.asked {
+				t.Fatalf("asked %d times, want %d", len(f.hints), tt.asked)
+			}
+			for i, hint := range f.hints {
+				if retry := strings.HasPrefix(hint, "Wrong, try again."); retry != (i > 0) {
+					t.Errorf("hint %d = %q", i, hint)
+				}
+			}
+		})
+	}
+
+	if d, _ := (quizConfig{pool: pool}).step(AuthAttempt{}); d != Continue {
+		t.Errorf("public key attempt: %v, want continue", d)
+	}
+}
+
+func TestQuizStepTwoQuestions(t *testing.T) {
+	pool := []Quiz{
+		{Question: "Best ide?", Answers: []string{"vim"}},
+		{Question: "Best shell?", Answers: []string{"zsh"}},
+	}
+	answer := map[string]string{"Best ide?": "vim", "Best shell?": "zsh"}
+	challenger := func(_, _ string, questions []string, _ []bool) ([]string, error) {
+		answers := make([]string, len(questions))
+		for i, q := range questions {
+			answers[i] = answer[q]
+		}
+		return answers, nil
+	}
+	// pick is random, one or two questions, both must come out right.
+	for range 20 {
+		if d, _ := (quizConfig{pool: pool}).step(AuthAttempt{Challenger: challenger}); d != Allow {
+			t.Fatalf("step = %v with every answer right", d)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ssh
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// A key from an authorized_keys file and the comment after it, usually
+// user@host.
+type AuthorizedKey struct {
+	Key     ssh.PublicKey
+	Comment string
+}
+
+// Keys from an authorized_keys style file, a missing file is no keys.
+func LoadAuthorizedKeys(path string) ([]AuthorizedKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []AuthorizedKey
+	for len(data) > 0 {
+		// Skips lines it can't parse, only errors once no key is left.
+		key, comment, _, rest, err := gossh.ParseAuthorizedKey(data)
+		if err != nil {
+			break
+		}
+		keys = append(keys, AuthorizedKey{Key: key, Comment: comment})
+		data = rest
+	}
+	return keys, nil
+}
+
+type authorizedKeyCtx struct{}
+
+// The authorized key the session logged in with, if it used one.
+func AuthorizedKeyFrom(ctx ssh.Context) (AuthorizedKey, bool) {
+	k, ok := ctx.Value(authorizedKeyCtx{}).(AuthorizedKey)
+	return k, ok
+}
+
+func findAuthorizedKey(keys []AuthorizedKey, key ssh.PublicKey) (AuthorizedKey, bool) {
+	if key == nil {
+		return AuthorizedKey{}, false
+	}
+	for _, k := range keys {
+		if ssh.KeysEqual(key, k.Key) {
+			return k, true
+		}
+	}
+	return AuthorizedKey{}, false
+}
+
+// Visitors with a listed key skip the quiz. The key isn't recorded here:
+// this also runs for keys a client only asks about without signing, see
+// authorizedKeyMiddleware.
+func authorizedKeyStep(keys []AuthorizedKey) func(a AuthAttempt) (Decision, string) {
+	return func(a AuthAttempt) (Decision, string) {
+		if a.PublicKey == nil {
+			return Continue, "no public key"
+		}
+		if k, ok := findAuthorizedKey(keys, a.PublicKey); ok {
+			return Allow, "authorized key " + gossh.FingerprintSHA256(k.Key)
+		}
+		return Continue, "key not authorized"
+	}
+}
+
+// Records the authorized key matching the key the session verified with,
+// for AuthorizedKeyFrom. A visitor who got in some other way has no key
+// here, whatever keys they offered first.
+func authorizedKeyMiddleware(keys []AuthorizedKey) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			if k, ok := findAuthorizedKey(keys, s.PublicKey()); ok {
+				s.Context().SetValue(authorizedKeyCtx{}, k)
+			}
+			next(s)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ssh
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// Commands maps an ssh command (e.g. `ssh willx86.com projects`) to the
+// plain text it prints.
+type Commands map[string]func() string
+
+// Runs before activeterm so piped, PTY-less sessions get plain text.
+// Sessions without a command fall through to the TUI untouched.
+func commandMiddleware(commands Commands) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			args := s.Command()
+			if len(args) == 0 {
+				next(s)
+				return
+			}
+
+			render, ok := commands[args[0]]
+			if !ok || len(args) > 1 {
+				wish.Errorln(s, "usage: ssh willx86.com ["+strings.Join(commands.names(), "|")+"]")
+				_ = s.Exit(1)
+				return
+			}
+			wish.Println(s, strings.TrimSpace(render()))
+			_ = s.Exit(0)
+		}
+	}
+}
+
+func (c Commands) names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
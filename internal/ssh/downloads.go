@@ -0,0 +1,116 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/scp"
+)
+
+// Downloads maps a file name to its contents for `scp willx86.com:name .`.
+// It's the whole filesystem scp sees, flat and read only. A func returning
+// fs.ErrNotExist hides the file, e.g. a resume that isn't on disk.
+type Downloads map[string]func() ([]byte, error)
+
+// scp commands are handled here, everything else carries on to the
+// commands and the TUI.
+func downloadsMiddleware(d Downloads) wish.Middleware {
+	return scp.Middleware(d, readOnly{})
+}
+
+func (d Downloads) names() []string {
+	var names []string
+	for name, read := range d {
+		if _, err := read(); err == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d Downloads) Glob(_ ssh.Session, pattern string) ([]string, error) {
+	pattern = clean(pattern)
+	var matches []string
+	for _, name := range d.names() {
+		if ok, _ := path.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+	return matches, nil
+}
+
+func (d Downloads) WalkDir(_ ssh.Session, root string, fn fs.WalkDirFunc) error {
+	if clean(root) != "." {
+		return fn(root, nil, fs.ErrNotExist)
+	}
+	for _, name := range d.names() {
+		if err := fn(name, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d Downloads) NewDirEntry(_ ssh.Session, name string) (*scp.DirEntry, error) {
+	if clean(name) != "." {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	now := time.Now().Unix()
+	return &scp.DirEntry{Name: ".", Filepath: ".", Mode: fs.ModeDir | 0o555, Mtime: now, Atime: now}, nil
+}
+
+func (d Downloads) NewFileEntry(s ssh.Session, name string) (*scp.FileEntry, func() error, error) {
+	name = clean(name)
+	read, ok := d[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	data, err := read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	log.Info("Download", "file", name, "bytes", len(data), "remote", s.RemoteAddr().String())
+	now := time.Now().Unix()
+	return &scp.FileEntry{
+		Name:     name,
+		Filepath: name,
+		Mode:     0o444,
+		Size:     int64(len(data)),
+		Reader:   bytes.NewReader(data),
+		Mtime:    now,
+		Atime:    now,
+	}, nil, nil
+}
+
+// Flattens whatever path the client asked for, nothing outside the
+// listed names can be reached.
+func clean(name string) string {
+	name = path.Clean("/" + strings.TrimSpace(name))
+	if name == "/" {
+		return "."
+	}
+	return strings.TrimPrefix(name, "/")
+}
+
+// Rejects every upload.
+type readOnly struct{}
+
+func (readOnly) Mkdir(s ssh.Session, _ *scp.DirEntry) error {
+	log.Warn("Rejected scp upload", "remote", s.RemoteAddr().String())
+	return fs.ErrPermission
+}
+
+func (readOnly) Write(s ssh.Session, e *scp.FileEntry) (int64, error) {
+	log.Warn("Rejected scp upload", "file", e.Name, "remote", s.RemoteAddr().String())
+	return 0, fs.ErrPermission
+}
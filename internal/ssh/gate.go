@@ -0,0 +1,229 @@
+package ssh
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+type GateConfig struct {
+	MaxFailures   int           // failed quiz answers before a ban
+	FailureWindow time.Duration // failures older than this are forgotten
+	BanDuration   time.Duration
+	MaxConnsPerIP int // 0 disables the cap
+	// Sessions per IP allowed to start within ConnWindow, 0 disables.
+	ConnRate   int
+	ConnWindow time.Duration
+}
+
+// Per-IP brute force protection, all state is in memory and expires.
+type Gate struct {
+	cfg GateConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	banned   map[string]time.Time
+	conns    map[string]int
+	sessions map[string][]time.Time // recent session starts per IP
+}
+
+func NewGate(cfg GateConfig) *Gate {
+	return &Gate{
+		cfg:      cfg,
+		now:      time.Now,
+		failures: map[string][]time.Time{},
+		banned:   map[string]time.Time{},
+		conns:    map[string]int{},
+		sessions: map[string][]time.Time{},
+	}
+}
+
+func (g *Gate) option() ssh.Option {
+	return func(srv *ssh.Server) error {
+		srv.ConnCallback = g.connCallback
+		return nil
+	}
+}
+
+// Refuses banned or over-cap IPs before the handshake, returning nil
+// makes the server drop the connection.
+func (g *Gate) connCallback(_ ssh.Context, conn net.Conn) net.Conn {
+	ip := remoteIP(conn.RemoteAddr())
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.isBannedLocked(ip) {
+		log.Warn("Refusing connection from banned IP", "ip", ip, "until", g.banned[ip].Format(time.RFC3339))
+		return nil
+	}
+	if g.cfg.MaxConnsPerIP > 0 && g.conns[ip] >= g.cfg.MaxConnsPerIP {
+		log.Warn("Refusing connection, too many from IP", "ip", ip, "open", g.conns[ip])
+		return nil
+	}
+	g.conns[ip]++
+	return &gatedConn{Conn: conn, release: func() { g.release(ip) }}
+}
+
+func (g *Gate) release(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conns[ip]--; g.conns[ip] <= 0 {
+		delete(g.conns, ip)
+	}
+}
+
+// Turns away sessions from IPs starting them faster than ConnRate per
+// ConnWindow, with a message rather than a dropped connection.
+func (g *Gate) rateMiddleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ip := remoteIP(s.RemoteAddr())
+			if !g.allowSession(ip) {
+				log.Warn("Rate limiting sessions from IP", "ip", ip, "limit", g.cfg.ConnRate, "window", g.cfg.ConnWindow)
+				wish.Fatalln(s, "Too many connections, please wait a minute and try again.")
+				return
+			}
+			next(s)
+		}
+	}
+}
+
+// Sliding window, only starts that were let through count.
+func (g *Gate) allowSession(ip string) bool {
+	if g.cfg.ConnRate <= 0 {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	// Forget IPs that have gone quiet so the map doesn't grow forever.
+	if len(g.sessions) > 1024 {
+		for k, starts := range g.sessions {
+			if len(starts) == 0 || now.Sub(starts[len(starts)-1]) >= g.cfg.ConnWindow {
+				delete(g.sessions, k)
+			}
+		}
+	}
+
+	recent := g.sessions[ip][:0]
+	for _, t := range g.sessions[ip] {
+		if now.Sub(t) < g.cfg.ConnWindow {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= g.cfg.ConnRate {
+		g.sessions[ip] = recent
+		return false
+	}
+	g.sessions[ip] = append(recent, now)
+	return true
+}
+
+func (g *Gate) banStep(a AuthAttempt) (Decision, string) {
+	ip := remoteIP(a.Ctx.RemoteAddr())
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.isBannedLocked(ip) {
+		return Deny, "ip banned"
+	}
+	return Continue, "not banned"
+}
+
+// Wrong quiz answers count as failures, public key denials don't since
+// clients offer every key they have before the quiz.
+func (g *Gate) observe(a AuthAttempt, d Decision) {
+	if d != Deny || a.Method != "keyboard-interactive" {
+		return
+	}
+	g.Fail(remoteIP(a.Ctx.RemoteAddr()))
+}
+
+// Records a failure for ip, banning it once it hits MaxFailures.
+func (g *Gate) Fail(ip string) {
+	if g.cfg.MaxFailures <= 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	// Same as allowSession, IPs that stopped failing are forgotten, and
+	// so are bans nobody came back to check.
+	if len(g.failures) > 1024 {
+		for k, times := range g.failures {
+			if len(times) == 0 || now.Sub(times[len(times)-1]) >= g.cfg.FailureWindow {
+				delete(g.failures, k)
+			}
+		}
+	}
+	if len(g.banned) > 1024 {
+		for k, until := range g.banned {
+			if now.After(until) {
+				delete(g.banned, k)
+			}
+		}
+	}
+
+	recent := g.failures[ip][:0]
+	for _, t := range g.failures[ip] {
+		if now.Sub(t) < g.cfg.FailureWindow {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+
+	if len(recent) >= g.cfg.MaxFailures {
+		g.banned[ip] = now.Add(g.cfg.BanDuration)
+		delete(g.failures, ip)
+		log.Warn("Banning IP after failed auth attempts", "ip", ip, "failures", len(recent), "for", g.cfg.BanDuration)
+		return
+	}
+	g.failures[ip] = recent
+}
+
+func (g *Gate) Banned(ip string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.isBannedLocked(ip)
+}
+
+// Expired bans are cleared as they're looked at.
+func (g *Gate) isBannedLocked(ip string) bool {
+	until, ok := g.banned[ip]
+	if !ok {
+		return false
+	}
+	if g.now().After(until) {
+		delete(g.banned, ip)
+		return false
+	}
+	return true
+}
+
+type gatedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *gatedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
+
+func remoteIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,139 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A gate whose clock only moves when the test says so.
+func newTestGate(cfg GateConfig) (*Gate, *time.Time) {
+	g := NewGate(cfg)
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	g.now = func() time.Time { return now }
+	return g, &now
+}
+
+func TestGateBanExpires(t *testing.T) {
+	g, now := newTestGate(GateConfig{MaxFailures: 3, FailureWindow: time.Minute, BanDuration: time.Hour})
+	g.Fail("192.0.2.1")
+	g.Fail("192.0.2.1")
+	if g.Banned("192.0.2.1") {
+		t.Fatal("banned before MaxFailures")
+	}
+	g.Fail("192.0.2.1")
+	if !g.Banned("192.0.2.1") {
+		t.Fatal("not banned at MaxFailures")
+	}
+
+	*now = now.Add(time.Hour)
+	if !g.Banned("192.0.2.1") {
+		t.Error("ban lifted at exactly BanDuration, it lasts through it")
+	}
+	*now = now.Add(time.Second)
+	if g.Banned("192.0.2.1") {
+		t.Fatal("still banned after BanDuration")
+	}
+	if _, ok := g.banned["192.0.2.1"]; ok {
+		t.Error("expired ban not cleared")
+	}
+
+	// The failures that earned the ban don't count towards the next one.
+	g.Fail("192.0.2.1")
+	if g.Banned("192.0.2.1") {
+		t.Error("re-banned on the first failure after a ban")
+	}
+}
+
+func TestGateFailuresOutsideWindow(t *testing.T) {
+	g, now := newTestGate(GateConfig{MaxFailures: 3, FailureWindow: time.Minute, BanDuration: time.Hour})
+	g.Fail("192.0.2.1")
+	g.Fail("192.0.2.1")
+	*now = now.Add(time.Minute)
+	g.Fail("192.0.2.1")
+	if g.Banned("192.0.2.1") {
+		t.Error("banned counting failures older than FailureWindow")
+	}
+	if n := len(g.failures["192.0.2.1"]); n != 1 {
+		t.Errorf("%d failures kept, want 1", n)
+	}
+}
+
+func TestGateConnCallbackRefusesBanned(t *testing.T) {
+	g, now := newTestGate(GateConfig{MaxFailures: 1, FailureWindow: time.Minute, BanDuration: time.Minute})
+	g.Fail("127.0.0.1")
+	client, conn := net.Pipe()
+	defer client.Close()
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}
+	c := addrConn{Conn: conn, remote: addr}
+	if g.connCallback(nil, c) != nil {
+		t.Fatal("banned IP let through")
+	}
+	*now = now.Add(time.Minute + time.Second)
+	gated := g.connCallback(nil, c)
+	if gated == nil {
+		t.Fatal("IP still refused after its ban")
+	}
+	if g.conns["127.0.0.1"] != 1 {
+		t.Errorf("open conns = %d, want 1", g.conns["127.0.0.1"])
+	}
+	_ = gated.Close()
+	if _, ok := g.conns["127.0.0.1"]; ok {
+		t.Error("closed conn still counted")
+	}
+}
+
+type addrConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c addrConn) RemoteAddr() net.Addr { return c.remote }
+
+// Failures and bans from IPs that went away are dropped once there are
+// enough of them, like allowSession does for session starts.
+func TestGatePrunesStaleIPs(t *testing.T) {
+	g, now := newTestGate(GateConfig{MaxFailures: 2, FailureWindow: time.Minute, BanDuration: time.Hour})
+	for i := range 1100 {
+		ip := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		g.Fail(ip)
+		if i%2 == 0 {
+			g.Fail(ip)
+		}
+	}
+	if len(g.failures) != 550 || len(g.banned) != 550 {
+		t.Fatalf("%d failing, %d banned, want 550 each", len(g.failures), len(g.banned))
+	}
+	// Not enough of either yet to bother.
+	*now = now.Add(2 * time.Hour)
+	g.Fail("192.0.2.1")
+	if len(g.failures) != 551 || len(g.banned) != 550 {
+		t.Fatalf("pruned early: %d failing, %d banned", len(g.failures), len(g.banned))
+	}
+
+	// Pushes both maps over the limit, the first phase's entries are
+	// all stale by now.
+	for i := range 600 {
+		g.Fail(fmt.Sprintf("10.1.%d.%d", i/256, i%256))
+		g.Fail(fmt.Sprintf("10.1.%d.%d", i/256, i%256))
+		g.Fail(fmt.Sprintf("10.2.%d.%d", i/256, i%256))
+	}
+	for ip := range g.failures {
+		if strings.HasPrefix(ip, "10.0.") {
+			t.Fatalf("stale failures for %s kept", ip)
+		}
+	}
+	for ip := range g.banned {
+		if strings.HasPrefix(ip, "10.0.") {
+			t.Fatalf("expired ban for %s kept", ip)
+		}
+	}
+	if len(g.failures) != 601 || len(g.banned) != 600 {
+		t.Errorf("%d failing, %d banned, want the 601 and 600 live ones", len(g.failures), len(g.banned))
+	}
+	if !g.Banned("10.1.0.1") {
+		t.Error("live ban pruned")
+	}
+}
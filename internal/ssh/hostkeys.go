@@ -0,0 +1,108 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Host keys are the id_* / *_key files in dir (ed25519, RSA, ...), an
+// ed25519 key is generated when there are none.
+func loadHostKeys(dir string) ([]gossh.Signer, error) {
+	paths, err := hostKeyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		path, err := generateHostKey(dir)
+		if err != nil {
+			return nil, fmt.Errorf("generating host key in %s: %w", dir, err)
+		}
+		paths = []string{path}
+	}
+
+	var signers []gossh.Signer
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading host key: %w", err)
+		}
+		signer, err := gossh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing host key %s: %w", path, err)
+		}
+		log.Info("Loaded host key", "path", path, "type", signer.PublicKey().Type(),
+			"fingerprint", gossh.FingerprintSHA256(signer.PublicKey()))
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+func hostKeyFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading host key dir: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasSuffix(name, ".pub") {
+			continue
+		}
+		if strings.HasPrefix(name, "id_") || strings.HasSuffix(name, "_key") {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths, nil
+}
+
+func generateHostKey(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	block, err := gossh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return "", err
+	}
+	sshPub, err := gossh.NewPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path+".pub", gossh.MarshalAuthorizedKey(sshPub), 0o644); err != nil {
+		return "", err
+	}
+	log.Info("Generated host key", "path", path, "fingerprint", gossh.FingerprintSHA256(sshPub))
+	return path, nil
+}
+
+func withHostKeys(signers []gossh.Signer) ssh.Option {
+	return func(srv *ssh.Server) error {
+		for _, s := range signers {
+			srv.AddHostKey(s)
+		}
+		return nil
+	}
+}
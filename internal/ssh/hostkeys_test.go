@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func fingerprints(signers []gossh.Signer) []string {
+	var fps []string
+	for _, s := range signers {
+		fps = append(fps, gossh.FingerprintSHA256(s.PublicKey()))
+	}
+	return fps
+}
+
+func TestHostKeyGeneratedThenReused(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys") // not there yet
+	first, err := loadHostKeys(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 1 || first[0].PublicKey().Type() != gossh.KeyAlgoED25519 {
+		t.Fatalf("generated %v, want one ed25519 key", fingerprints(first))
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "id_ed25519"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("private key mode %o, want 600", perm)
+	}
+	pub, err := os.ReadFile(filepath.Join(dir, "id_ed25519.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, _, _, err := gossh.ParseAuthorizedKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gossh.FingerprintSHA256(parsed) != fingerprints(first)[0] {
+		t.Error(".pub doesn't match the private key")
+	}
+
+	// .pub sits next to the key, it has to be skipped rather than parsed
+	// as a second private key.
+	second, err := loadHostKeys(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 1 || fingerprints(second)[0] != fingerprints(first)[0] {
+		t.Errorf("reloaded %v, want the generated %v", fingerprints(second), fingerprints(first))
+	}
+}
+
+func TestHostKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := gossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		"ssh_host_rsa_key":     pem.EncodeToMemory(block),
+		"ssh_host_rsa_key.pub": []byte("not a private key"),
+		"id_ed25519.pub":       []byte("not a private key"),
+		"authorized_keys":      []byte("not a host key"),
+		"README":               []byte("not a host key"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "id_dir"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	signers, err := loadHostKeys(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signers) != 1 || signers[0].PublicKey().Type() != gossh.KeyAlgoRSA {
+		t.Fatalf("loaded %v, want only the RSA key", fingerprints(signers))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "id_ed25519")); !os.IsNotExist(err) {
+		t.Error("generated a key with one already there")
+	}
+}
+
+func TestHostKeyUnparsable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "id_ed25519"), []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadHostKeys(dir); err == nil {
+		t.Error("loaded a garbage key")
+	}
+}
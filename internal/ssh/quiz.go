@@ -0,0 +1,150 @@
+package ssh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// A keyboard-interactive question visitors answer to get in.
+type Quiz struct {
+	Question string
+	Hint     string // shown above the question
+	Answers  []string
+	// Otherwise answers are compared lowercased.
+	CaseSensitive bool
+}
+
+// The original, best ide is vim.
+var DefaultQuiz = Quiz{
+	Question: "What is the best ide?",
+	Hint:     `Possible answers are "vim" or "other"`,
+	Answers:  []string{"vim"},
+}
+
+func (q Quiz) accepts(answer string) bool {
+	answer = strings.TrimSpace(answer)
+	for _, want := range q.Answers {
+		want = strings.TrimSpace(want)
+		if q.CaseSensitive && answer == want || !q.CaseSensitive && strings.EqualFold(answer, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads a question pool, one per line as
+//
+//	question | answer, other answer
+//
+// Blank lines and lines starting with # are skipped. A missing file is an
+// empty pool, anything malformed is an error naming the line.
+func LoadQuestions(path string) ([]Quiz, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pool []Quiz
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		q, err := parseQuestion(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		pool = append(pool, q)
+	}
+	return pool, sc.Err()
+}
+
+func parseQuestion(line string) (Quiz, error) {
+	question, answers, ok := strings.Cut(line, "|")
+	if !ok {
+		return Quiz{}, errors.New(`want "question | answers"`)
+	}
+	q := Quiz{Question: strings.TrimSpace(question)}
+	if q.Question == "" {
+		return Quiz{}, errors.New("empty question")
+	}
+	for _, a := range strings.Split(answers, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			q.Answers = append(q.Answers, a)
+		}
+	}
+	if len(q.Answers) == 0 {
+		return Quiz{}, errors.New("no answers")
+	}
+	return q, nil
+}
+
+// How the quiz step asks: 1-2 random questions from the pool, asked
+// again up to retries times on a wrong answer.
+type quizConfig struct {
+	pool    []Quiz
+	retries int
+}
+
+// Picks up to two distinct questions, only one if that's all there is.
+func (c quizConfig) pick() []Quiz {
+	n := min(1+rand.IntN(2), len(c.pool))
+	picked := make([]Quiz, 0, n)
+	for _, i := range rand.Perm(len(c.pool))[:n] {
+		picked = append(picked, c.pool[i])
+	}
+	return picked
+}
+
+func (c quizConfig) step(a AuthAttempt) (Decision, string) {
+	if a.Challenger == nil {
+		return Continue, "not keyboard-interactive"
+	}
+	for try := 0; try <= c.retries; try++ {
+		questions := c.pick()
+		prompts := make([]string, len(questions))
+		echo := make([]bool, len(questions))
+		for i, q := range questions {
+			prompts[i] = q.Question
+			echo[i] = true
+		}
+		hint := questions[0].Hint
+		if len(questions) > 1 {
+			hint = "Answer both questions"
+		}
+		if try > 0 {
+			hint = "Wrong, try again. " + hint
+		}
+
+		answers, err := a.Challenger("", hint, prompts, echo)
+		if err != nil {
+			log.Error("Error with answers", "error", err)
+			return Deny, "challenge failed"
+		}
+		if len(answers) == len(questions) && allCorrect(questions, answers) {
+			return Allow, "correct answer"
+		}
+	}
+	return Deny, "wrong answer"
+}
+
+func allCorrect(questions []Quiz, answers []string) bool {
+	for i, q := range questions {
+		if !q.accepts(answers[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeQuestions(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "questions.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadQuestions(t *testing.T) {
+	path := writeQuestions(t, `# questions for the ssh quiz
+
+What is the best ide? | vim, neovim ,
+   # indented comment
+  What does ls do?|lists files
+
+What's 2+2? | 4,four
+`)
+	pool, err := LoadQuestions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Quiz{
+		{Question: "What is the best ide?", Answers: []string{"vim", "neovim"}},
+		{Question: "What does ls do?", Answers: []string{"lists files"}},
+		{Question: "What's 2+2?", Answers: []string{"4", "four"}},
+	}
+	if !reflect.DeepEqual(pool, want) {
+		t.Errorf("LoadQuestions = %+v\nwant %+v", pool, want)
+	}
+}
+
+func TestLoadQuestionsEmpty(t *testing.T) {
+	for name, path := range map[string]string{
+		"missing":       filepath.Join(t.TempDir(), "nope.txt"),
+		"only comments": writeQuestions(t, "# nothing yet\n\n   \n"),
+	} {
+		pool, err := LoadQuestions(path)
+		if err != nil || len(pool) != 0 {
+			t.Errorf("%s: %v, %v; want an empty pool", name, pool, err)
+		}
+	}
+}
+
+func TestLoadQuestionsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, text string
+		line       int
+		msg        string
+	}{
+		{"missing |", "ok? | yes\n\nno separator here\n", 3, `want "question | answers"`},
+		{"empty answers", "# header\nok? |  , ,\n", 2, "no answers"},
+		{"nothing after |", "ok? |\n", 1, "no answers"},
+		{"empty question", "ok? | yes\n | yes\n", 2, "empty question"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeQuestions(t, tt.text)
+			_, err := LoadQuestions(path)
+			if err == nil {
+				t.Fatal("malformed pool loaded")
+			}
+			if prefix := fmt.Sprintf("%s:%d: ", path, tt.line); !strings.HasPrefix(err.Error(), prefix) {
+				t.Errorf("error %q doesn't start with %q", err, prefix)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("error %q doesn't say %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestQuizAccepts(t *testing.T) {
+	q := Quiz{Answers: []string{"Vim", " neovim "}}
+	for answer, want := range map[string]bool{"vim": true, " VIM ": true, "neovim": true, "emacs": false, "": false} {
+		if got := q.accepts(answer); got != want {
+			t.Errorf("accepts(%q) = %v, want %v", answer, got, want)
+		}
+	}
+	q.CaseSensitive = true
+	if q.accepts("vim") || !q.accepts("Vim") {
+		t.Error("case sensitive quiz compared lowercased")
+	}
+}
@@ -0,0 +1,210 @@
+package ssh
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/muesli/termenv"
+)
+
+// Records what each TUI session draws to an asciinema v2 cast in dir, to
+// replay rendering bugs from terminals we don't have. Sessions with a
+// real pty write to its slave and aren't seen, the server only gives out
+// emulated ones.
+func recordMiddleware(dir string, limit int64) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			pty, windows, ok := s.Pty()
+			if dir == "" || !ok {
+				next(s)
+				return
+			}
+			rec, err := newRecorder(dir, s, pty, limit)
+			if err != nil {
+				log.Error("Could not start recording", "error", err, "remote", s.RemoteAddr().String())
+				next(s)
+				return
+			}
+			defer rec.close()
+
+			// The UI still gets every resize, after it's been recorded.
+			resized := make(chan ssh.Window, 1)
+			go func() {
+				for {
+					select {
+					case <-s.Context().Done():
+						return
+					case w := <-windows:
+						rec.event("r", fmt.Sprintf("%dx%d", w.Width, w.Height))
+						select {
+						case resized <- w:
+						case <-s.Context().Done():
+							return
+						}
+					}
+				}
+			}()
+			next(&recordedSession{Session: s, rec: rec, windows: resized})
+		}
+	}
+}
+
+type recordedSession struct {
+	ssh.Session
+	rec     *recorder
+	windows chan ssh.Window
+}
+
+func (s *recordedSession) Write(p []byte) (int, error) {
+	s.rec.output(p)
+	return s.Session.Write(p)
+}
+
+func (s *recordedSession) Pty() (ssh.Pty, <-chan ssh.Window, bool) {
+	pty, _, ok := s.Session.Pty()
+	return pty, s.windows, ok
+}
+
+// One cast file, buffered so recording doesn't hold up drawing and
+// flushed when the session ends.
+type recorder struct {
+	mu      sync.Mutex
+	f       *os.File
+	w       *bufio.Writer
+	start   time.Time
+	limit   int64 // bytes, 0 for no cap
+	written int64
+	full    bool
+	partial []byte // end of a rune split across writes
+}
+
+// Cast header as in https://docs.asciinema.org/manual/asciicast/v2/
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title"`
+	Env       map[string]string `json:"env"`
+}
+
+func newRecorder(dir string, s ssh.Session, pty ssh.Pty, limit int64) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	addr := strings.NewReplacer(":", "_", "[", "", "]", "").Replace(s.RemoteAddr().String())
+	path := filepath.Join(dir, now.UTC().Format("20060102T150405Z")+"-"+addr+".cast")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{"TERM": pty.Term}
+	environ := append(s.Environ(), "TERM="+pty.Term)
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && (k == "COLORTERM" || k == "NO_COLOR") {
+			env[k] = v
+		}
+	}
+	// Same guess lipgloss makes for the session, without querying it.
+	profile := termenv.NewOutput(io.Discard, termenv.WithEnvironment(sessionEnv(environ)), termenv.WithUnsafe()).EnvColorProfile()
+
+	header, err := json.Marshal(castHeader{
+		Version:   2,
+		Width:     pty.Window.Width,
+		Height:    pty.Window.Height,
+		Timestamp: now.Unix(),
+		Title:     fmt.Sprintf("%s@%s, %s", s.User(), s.RemoteAddr(), profile.Name()),
+		Env:       env,
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r := &recorder{f: f, w: bufio.NewWriter(f), start: now, limit: limit}
+	r.w.Write(append(header, '\n'))
+	r.written = int64(len(header) + 1)
+	log.Info("Recording session", "path", path)
+	return r, nil
+}
+
+// Queues p as an output event, holding back a trailing partial rune so
+// each event is valid UTF-8 like the format wants.
+func (r *recorder) output(p []byte) {
+	r.mu.Lock()
+	data := append(r.partial, p...)
+	cut := len(data)
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				cut = len(data) - i
+			}
+			break
+		}
+	}
+	r.partial = append([]byte(nil), data[cut:]...)
+	r.mu.Unlock()
+	if cut > 0 {
+		r.event("o", string(data[:cut]))
+	}
+}
+
+func (r *recorder) event(kind, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return
+	}
+	line, err := json.Marshal([]any{r.elapsed(), kind, data})
+	if err != nil {
+		return
+	}
+	if r.limit > 0 && r.written+int64(len(line))+1 > r.limit {
+		// A marker so a short cast isn't mistaken for a short session.
+		r.full = true
+		line, _ = json.Marshal([]any{r.elapsed(), "m", "recording size limit reached"})
+	}
+	r.w.Write(append(line, '\n'))
+	r.written += int64(len(line) + 1)
+}
+
+// Seconds since the start, to the microsecond.
+func (r *recorder) elapsed() float64 {
+	return math.Round(time.Since(r.start).Seconds()*1e6) / 1e6
+}
+
+func (r *recorder) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.full = true
+	if err := r.w.Flush(); err != nil {
+		log.Error("Could not write recording", "error", err, "path", r.f.Name())
+	}
+	r.f.Close()
+}
+
+type sessionEnv []string
+
+func (e sessionEnv) Environ() []string { return e }
+
+func (e sessionEnv) Getenv(key string) string {
+	for _, kv := range e {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// Enough of ssh.Session for the recorder, what's written is kept.
+type fakeSession struct {
+	ssh.Session
+	ctx     *fakeCtx
+	env     []string
+	pty     ssh.Pty
+	windows chan ssh.Window
+	out     bytes.Buffer
+	pub     ssh.PublicKey // verified key, nil for the quiz
+}
+
+func newFakeSession(env ...string) *fakeSession {
+	return &fakeSession{
+		ctx:     newFakeCtx("192.0.2.1:2222"),
+		env:     env,
+		pty:     ssh.Pty{Term: "xterm-256color", Window: ssh.Window{Width: 80, Height: 24}},
+		windows: make(chan ssh.Window),
+	}
+}
+
+func (s *fakeSession) User() string                            { return "visitor" }
+func (s *fakeSession) RemoteAddr() net.Addr                    { return s.ctx.RemoteAddr() }
+func (s *fakeSession) Environ() []string                       { return s.env }
+func (s *fakeSession) Context() ssh.Context                    { return s.ctx }
+func (s *fakeSession) PublicKey() ssh.PublicKey                { return s.pub }
+func (s *fakeSession) Write(p []byte) (int, error)             { return s.out.Write(p) }
+func (s *fakeSession) Pty() (ssh.Pty, <-chan ssh.Window, bool) { return s.pty, s.windows, true }
+
+type castEvent struct {
+	time       float64
+	kind, data string
+}
+
+// The header and events of the one cast in dir.
+func readCast(t *testing.T, dir string) (castHeader, []castEvent, int64) {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.cast"))
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("casts in %s: %v, %v", dir, paths, err)
+	}
+	if !strings.HasSuffix(paths[0], "-192.0.2.1_2222.cast") {
+		t.Errorf("cast named %s", filepath.Base(paths[0]))
+	}
+	f, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, _ := f.Stat()
+
+	sc := bufio.NewScanner(f)
+	var header castHeader
+	if !sc.Scan() {
+		t.Fatal("empty cast")
+	}
+	if err := json.Unmarshal(sc.Bytes(), &header); err != nil {
+		t.Fatalf("header %q: %v", sc.Text(), err)
+	}
+	var events []castEvent
+	for sc.Scan() {
+		var raw []any
+		if err := json.Unmarshal(sc.Bytes(), &raw); err != nil || len(raw) != 3 {
+			t.Fatalf("event %q: %v", sc.Text(), err)
+		}
+		at, ok1 := raw[0].(float64)
+		kind, ok2 := raw[1].(string)
+		data, ok3 := raw[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			t.Fatalf("event %q isn't [time, kind, data]", sc.Text())
+		}
+		events = append(events, castEvent{at, kind, data})
+	}
+	return header, events, info.Size()
+}
+
+func TestCastHeader(t *testing.T) {
+	dir := t.TempDir()
+	s := newFakeSession("COLORTERM=truecolor", "LANG=C", "NO_COLOR=1")
+	rec, err := newRecorder(dir, s, s.pty, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.close()
+
+	header, events, _ := readCast(t, dir)
+	if header.Version != 2 || header.Width != 80 || header.Height != 24 || header.Timestamp == 0 {
+		t.Errorf("header %+v", header)
+	}
+	want := map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor", "NO_COLOR": "1"}
+	if len(header.Env) != len(want) {
+		t.Errorf("env %v, want only %v", header.Env, want)
+	}
+	for k, v := range want {
+		if header.Env[k] != v {
+			t.Errorf("env %s = %q, want %q", k, header.Env[k], v)
+		}
+	}
+	if !strings.HasPrefix(header.Title, "visitor@192.0.2.1:2222, ") {
+		t.Errorf("title %q", header.Title)
+	}
+	if len(events) != 0 {
+		t.Errorf("events %v in an empty session", events)
+	}
+}
+
+// A rune split across writes is held back until it's whole, so every
+// event is valid UTF-8.
+func TestCastSplitRune(t *testing.T) {
+	dir := t.TempDir()
+	s := newFakeSession()
+	rec, err := newRecorder(dir, s, s.pty, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	word := []byte("a日本👋")
+	rec.output(word[:2])  // a, the first byte of 日
+	rec.output(word[2:6]) // the rest of 日, most of 本
+	rec.output(word[6:8]) // the end of 本, the start of 👋
+	rec.output(word[8:])
+	rec.output([]byte("\x1b[0m"))
+	rec.close()
+
+	_, events, _ := readCast(t, dir)
+	var got []string
+	for _, e := range events {
+		if e.kind != "o" {
+			t.Errorf("event kind %q", e.kind)
+		}
+		got = append(got, e.data)
+	}
+	if want := []string{"a", "日", "本", "👋", "\x1b[0m"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("events %q, want %q", got, want)
+	}
+}
+
+func TestCastSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	s := newFakeSession()
+	const limit = 1024
+	rec, err := newRecorder(dir, s, s.pty, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 100 {
+		rec.output([]byte("0123456789"))
+	}
+	rec.event("r", "100x30")
+	rec.close()
+
+	_, events, size := readCast(t, dir)
+	last := events[len(events)-1]
+	if last.kind != "m" || last.data != "recording size limit reached" {
+		t.Fatalf("last event %+v, want the limit marker", last)
+	}
+	for _, e := range events[:len(events)-1] {
+		if e.kind != "o" || e.data != "0123456789" {
+			t.Errorf("event %+v before the marker", e)
+		}
+	}
+	// Everything up to the marker fits, the marker itself may not.
+	if size > limit+100 {
+		t.Errorf("cast is %d bytes with a %d limit", size, limit)
+	}
+	if len(events) < 5 {
+		t.Errorf("only %d events under the limit", len(events))
+	}
+}
+
+// Through the middleware: output and resizes are recorded, and the
+// session still gets both.
+func TestRecordMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	s := newFakeSession()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx.Context = ctx
+	defer cancel()
+
+	recordMiddleware(dir, 0)(func(sess ssh.Session) {
+		_, windows, _ := sess.Pty()
+		go func() { s.windows <- ssh.Window{Width: 100, Height: 30} }()
+		if w := <-windows; w.Width != 100 || w.Height != 30 {
+			t.Errorf("handler got window %+v", w)
+		}
+		_, _ = sess.Write([]byte("hello"))
+	})(s)
+
+	if s.out.String() != "hello" {
+		t.Errorf("session got %q", s.out.String())
+	}
+	_, events, _ := readCast(t, dir)
+	if len(events) != 2 || events[0] != (castEvent{events[0].time, "r", "100x30"}) || events[1].kind != "o" || events[1].data != "hello" {
+		t.Errorf("events %+v", events)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].time < events[i-1].time {
+			t.Errorf("event times go backwards: %+v", events)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ssh
+
+import (
+	"net"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	"github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
+)
+
+type Config struct {
+	Host     string
+	Port     string
+	Handler  bubbletea.ProgramHandler
+	Commands Commands
+	// Files offered over scp.
+	Downloads Downloads
+	// Directory holding the host keys, see loadHostKeys.
+	HostKeyDir string
+	// Keys that skip the quiz, public key auth is only offered when
+	// there are any of these or AuthorizedKeys.
+	TrustedKeys []ssh.PublicKey
+	// Visitors' keys that skip the quiz, see LoadAuthorizedKeys.
+	AuthorizedKeys []AuthorizedKey
+	Gate           GateConfig
+	// Question for everyone else, DefaultQuiz when it has no answers.
+	Quiz Quiz
+	// Pool of questions asked instead of Quiz when not empty, see
+	// LoadQuestions.
+	Questions []Quiz
+	// Wrong answers allowed per connection before it's refused.
+	QuizRetries int
+	// Skip the quiz and let everyone in, banned IPs are still refused.
+	NoAuth bool
+	// Called with every final auth decision, after the ban bookkeeping.
+	AuthObservers []func(AuthAttempt, Decision)
+	// Directory TUI sessions are recorded to as asciinema casts, empty
+	// turns recording off. Casts stop at RecordLimit bytes, 0 for no cap.
+	RecordDir   string
+	RecordLimit int64
+	// Outermost middleware, runs before logging.
+	Middleware []wish.Middleware
+}
+
+func NewServer(cfg Config) (*ssh.Server, error) {
+	hostKeys, err := loadHostKeys(cfg.HostKeyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	gate := NewGate(cfg.Gate)
+	quiz := quizConfig{pool: cfg.Questions, retries: cfg.QuizRetries}
+	if len(quiz.pool) == 0 {
+		single := cfg.Quiz
+		if len(single.Answers) == 0 {
+			single = DefaultQuiz
+		}
+		quiz.pool = []Quiz{single}
+	}
+	chain := defaultAuthChain(cfg.TrustedKeys, cfg.AuthorizedKeys, quiz, cfg.NoAuth, gate)
+	chain.Observers = append(chain.Observers, cfg.AuthObservers...)
+	middleware := append([]wish.Middleware{
+		bubbletea.MiddlewareWithProgramHandler(cfg.Handler, termenv.Ascii),
+		recordMiddleware(cfg.RecordDir, cfg.RecordLimit),
+		activeterm.Middleware(),
+		commandMiddleware(cfg.Commands),
+		downloadsMiddleware(cfg.Downloads),
+		gate.rateMiddleware(),
+		authorizedKeyMiddleware(cfg.AuthorizedKeys),
+		logging.Middleware(),
+	}, cfg.Middleware...)
+
+	opts := []ssh.Option{
+		wish.WithAddress(net.JoinHostPort(cfg.Host, cfg.Port)),
+		withHostKeys(hostKeys),
+		gate.option(),
+		wish.WithKeyboardInteractiveAuth(chain.keyboardInteractive),
+		wish.WithMiddleware(middleware...),
+	}
+	if len(cfg.TrustedKeys) > 0 || len(cfg.AuthorizedKeys) > 0 {
+		opts = append(opts, wish.WithPublicKeyAuth(chain.publicKey))
+	}
+	srv, err := wish.NewServer(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
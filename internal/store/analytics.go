@@ -0,0 +1,181 @@
+package store
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// All-time visit counts, kept across restarts in analyticsPath.
+// Visitors aren't stored at all, only bits set by a keyed hash of them,
+// see RecordSession.
+var (
+	analyticsMu   sync.Mutex
+	analytics     = newAnalyticsData()
+	analyticsPath string
+)
+
+// Bits in analyticsData.Seen, 16 KiB estimates up to a few hundred
+// thousand visitors to within a couple of percent.
+const visitorBits = 1 << 17
+
+type analyticsData struct {
+	Sessions int64            `json:"sessions"`
+	Views    map[string]int64 `json:"views"`
+	// Random per install, keys the visitor hash so the bits can't be
+	// matched against hashes of every IP.
+	Salt []byte `json:"salt"`
+	Seen []byte `json:"seen"`
+	// Hashed visitors from before Seen, folded into it on load.
+	Visitors map[string]struct{} `json:"visitors,omitempty"`
+}
+
+func newAnalyticsData() analyticsData {
+	return analyticsData{Views: map[string]int64{}, Salt: newSalt(), Seen: make([]byte, visitorBits/8)}
+}
+
+func newSalt() []byte {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		panic(err)
+	}
+	return salt
+}
+
+// What the stats page and /stats show.
+type Analytics struct {
+	Sessions       int64            `json:"sessions"`
+	UniqueVisitors int              `json:"unique_visitors"`
+	Views          map[string]int64 `json:"views"`
+}
+
+// Counts a TUI session, visitor is their key fingerprint or, without
+// one, their IP. Each visitor sets one bit of Seen, picked by an HMAC
+// with the install's salt, and unique visitors are estimated from how
+// many are still clear (linear counting), so memory and the saved file
+// stay the same size however many visit.
+func RecordSession(visitor string) {
+	analyticsMu.Lock()
+	defer analyticsMu.Unlock()
+	analytics.Sessions++
+	analytics.see(visitor)
+}
+
+// Call with analyticsMu held.
+func (a *analyticsData) see(visitor string) {
+	mac := hmac.New(sha256.New, a.Salt)
+	mac.Write([]byte(visitor))
+	bit := binary.BigEndian.Uint64(mac.Sum(nil)) % visitorBits
+	a.Seen[bit/8] |= 1 << (bit % 8)
+}
+
+// Estimated visitors, exact while they're few next to visitorBits.
+// Call with analyticsMu held.
+func (a *analyticsData) uniqueVisitors() int {
+	unset := visitorBits
+	for _, b := range a.Seen {
+		unset -= bits.OnesCount8(b)
+	}
+	if unset == 0 {
+		unset = 1
+	}
+	return int(math.Round(visitorBits * math.Log(float64(visitorBits)/float64(unset))))
+}
+
+// Counts someone opening a section, cheap enough to call from Update.
+func RecordView(section string) {
+	analyticsMu.Lock()
+	defer analyticsMu.Unlock()
+	analytics.Views[section]++
+}
+
+func AnalyticsSnapshot() Analytics {
+	analyticsMu.Lock()
+	defer analyticsMu.Unlock()
+	views := make(map[string]int64, len(analytics.Views))
+	for k, v := range analytics.Views {
+		views[k] = v
+	}
+	return Analytics{
+		Sessions:       analytics.Sessions,
+		UniqueVisitors: analytics.uniqueVisitors(),
+		Views:          views,
+	}
+}
+
+// Loads saved counts from path and saves back to it every interval,
+// call SaveAnalytics on shutdown for the last few.
+func StartAnalytics(path string, interval time.Duration) error {
+	analyticsPath = path
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		log.Info("No saved analytics, starting from zero", "path", path)
+	case err != nil:
+		return err
+	default:
+		loaded := analyticsData{}
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return err
+		}
+		if loaded.Views == nil {
+			loaded.Views = map[string]int64{}
+		}
+		if len(loaded.Salt) == 0 {
+			loaded.Salt = newSalt()
+		}
+		if len(loaded.Seen) != visitorBits/8 {
+			loaded.Seen = make([]byte, visitorBits/8)
+		}
+		// Older files kept the hashes themselves. They can't be matched
+		// to the visitors again, so each counts once more if they return.
+		for id := range loaded.Visitors {
+			loaded.see(id)
+		}
+		loaded.Visitors = nil
+		analyticsMu.Lock()
+		analytics = loaded
+		analyticsMu.Unlock()
+	}
+
+	go func() {
+		for range time.Tick(interval) {
+			SaveAnalytics()
+		}
+	}()
+	return nil
+}
+
+// Written to a temp file and renamed so a crash can't leave half a file.
+func SaveAnalytics() {
+	if analyticsPath == "" {
+		return
+	}
+	analyticsMu.Lock()
+	data, err := json.Marshal(analytics)
+	analyticsMu.Unlock()
+	if err != nil {
+		log.Error("Could not encode analytics", "error", err)
+		return
+	}
+	tmp := filepath.Join(filepath.Dir(analyticsPath), "."+filepath.Base(analyticsPath)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Error("Could not save analytics", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, analyticsPath); err != nil {
+		log.Error("Could not save analytics", "error", err)
+	}
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func resetAnalytics(t *testing.T) {
+	t.Helper()
+	analyticsMu.Lock()
+	saved, savedPath := analytics, analyticsPath
+	analytics, analyticsPath = newAnalyticsData(), ""
+	analyticsMu.Unlock()
+	t.Cleanup(func() {
+		analyticsMu.Lock()
+		analytics, analyticsPath = saved, savedPath
+		analyticsMu.Unlock()
+	})
+}
+
+func TestUniqueVisitors(t *testing.T) {
+	resetAnalytics(t)
+	for range 10 {
+		RecordSession("198.51.100.7")
+	}
+	if a := AnalyticsSnapshot(); a.Sessions != 10 || a.UniqueVisitors != 1 {
+		t.Fatalf("one visitor ten times: %+v", a)
+	}
+
+	for i := range 5000 {
+		RecordSession(fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+	}
+	got := AnalyticsSnapshot().UniqueVisitors
+	if got < 4950 || got > 5050 {
+		t.Errorf("estimated %d unique visitors, want about 5001", got)
+	}
+}
+
+// The file holds the salt and bits, never the visitors or plain hashes
+// of them.
+func TestAnalyticsFileHasNoVisitors(t *testing.T) {
+	resetAnalytics(t)
+	path := filepath.Join(t.TempDir(), "analytics.json")
+	if err := StartAnalytics(path, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	RecordSession("198.51.100.7")
+	RecordSession("SHA256:abcdef")
+	SaveAnalytics()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, private := range []string{"198.51.100.7", "SHA256:abcdef", visitorID("198.51.100.7"), visitorID("SHA256:abcdef")} {
+		if bytes.Contains(data, []byte(private)) {
+			t.Errorf("analytics file contains %q", private)
+		}
+	}
+	if strings.Contains(string(data), `"visitors"`) {
+		t.Error("analytics file still has a visitors set")
+	}
+}
+
+func TestAnalyticsSaltPersists(t *testing.T) {
+	resetAnalytics(t)
+	path := filepath.Join(t.TempDir(), "analytics.json")
+	if err := StartAnalytics(path, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	RecordSession("198.51.100.7")
+	SaveAnalytics()
+	analyticsMu.Lock()
+	salt := analytics.Salt
+	analyticsMu.Unlock()
+
+	// Another install picks a different salt.
+	if other := newAnalyticsData(); bytes.Equal(other.Salt, salt) {
+		t.Fatal("two installs got the same salt")
+	}
+
+	analyticsMu.Lock()
+	analytics = newAnalyticsData()
+	analyticsMu.Unlock()
+	if err := StartAnalytics(path, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	analyticsMu.Lock()
+	reloaded := analytics.Salt
+	analyticsMu.Unlock()
+	if !bytes.Equal(reloaded, salt) {
+		t.Fatal("salt changed across a restart")
+	}
+
+	// The same visitor after the restart isn't counted again.
+	RecordSession("198.51.100.7")
+	if a := AnalyticsSnapshot(); a.Sessions != 2 || a.UniqueVisitors != 1 {
+		t.Errorf("after a restart: %+v, want 2 sessions from 1 visitor", a)
+	}
+}
+
+// Files from before the bitmap keep their count.
+func TestAnalyticsLegacyVisitors(t *testing.T) {
+	resetAnalytics(t)
+	path := filepath.Join(t.TempDir(), "analytics.json")
+	legacy := map[string]any{
+		"sessions": 7,
+		"views":    map[string]int64{"home": 7},
+		"visitors": map[string]struct{}{visitorID("a"): {}, visitorID("b"): {}, visitorID("c"): {}},
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartAnalytics(path, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if a := AnalyticsSnapshot(); a.Sessions != 7 || a.UniqueVisitors != 3 || a.Views["home"] != 7 {
+		t.Errorf("legacy file loaded as %+v", a)
+	}
+}
@@ -0,0 +1,34 @@
+package store
+
+// Up to limit stored messages with IDs after after, oldest first. IDs
+// sort in the order messages arrived, so after doesn't have to still be
+// stored, e.g. once it's been acked.
+func MessagesAfter(after string, limit int) []Message {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	page := []Message{}
+	for _, m := range messages {
+		if len(page) == limit {
+			break
+		}
+		if after == "" || m.ID > after {
+			page = append(page, m)
+		}
+	}
+	return page
+}
+
+// Removes the message with id, false if there's none, e.g. it was
+// already acked.
+func DeleteByID(id string) bool {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	for i := range messages {
+		if messages[i].ID == id {
+			messages = append(messages[:i], messages[i+1:]...)
+			queued.Store(int64(len(messages)))
+			return true
+		}
+	}
+	return false
+}
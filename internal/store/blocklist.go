@@ -0,0 +1,123 @@
+package store
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	blocklistMu sync.RWMutex
+	blocklist   []*regexp.Regexp
+)
+
+// Loads one pattern per line, /wrapped/ lines are regexes, lines with a
+// * or ? are globs (that stay within a word, so s*x doesn't match half a
+// sentence) and anything else is a plain substring. Matching is
+// case-insensitive, blank lines and # comments are skipped. Globs and
+// substrings are folded too (see fold), so "café" still matches once the
+// accent is gone. A missing file clears the list.
+func LoadBlocklist(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		blocklistMu.Lock()
+		blocklist = nil
+		blocklistMu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var patterns []*regexp.Regexp
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		exprs := []string{globExpr(line)}
+		if folded := fold(line); folded != line {
+			exprs = append(exprs, globExpr(folded))
+		}
+		if len(line) > 2 && strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/") {
+			exprs = []string{line[1 : len(line)-1]}
+		}
+		for _, expr := range exprs {
+			re, err := regexp.Compile("(?i)" + expr)
+			if err != nil {
+				log.Warn("Skipping invalid blocklist pattern", "pattern", line, "error", err)
+				break
+			}
+			patterns = append(patterns, re)
+		}
+	}
+
+	blocklistMu.Lock()
+	blocklist = patterns
+	blocklistMu.Unlock()
+	log.Info("Loaded blocklist", "patterns", len(patterns))
+	return nil
+}
+
+// A plain line as a regex, * and ? match within a word.
+func globExpr(line string) string {
+	return strings.NewReplacer(`\*`, `\S*`, `\?`, `\S`).Replace(regexp.QuoteMeta(line))
+}
+
+// Whether content matches the blocklist as written or with lookalike
+// characters folded, see fold.
+func Blocked(content string) bool {
+	blocklistMu.RLock()
+	defer blocklistMu.RUnlock()
+	if len(blocklist) == 0 {
+		return false
+	}
+	folded := fold(content)
+	for _, re := range blocklist {
+		if re.MatchString(content) || re.MatchString(folded) {
+			return true
+		}
+	}
+	return false
+}
+
+// Capitals that only look latin before lowercasing (Ν is N but ν is v),
+// replaced first.
+var capitalLookalikes = strings.NewReplacer(
+	// Greek
+	"Η", "h", "Μ", "m", "Ν", "n", "Υ", "y", "Ζ", "z",
+)
+
+// Letters from other scripts drawn like latin ones, NFKD already takes
+// care of fullwidth forms, ligatures and accents.
+var lookalikes = strings.NewReplacer(
+	// Cyrillic
+	"а", "a", "в", "b", "е", "e", "к", "k", "м", "m", "н", "h", "о", "o", "р", "p",
+	"с", "c", "т", "t", "у", "y", "х", "x", "і", "i", "ј", "j", "ѕ", "s", "ԁ", "d", "ӏ", "l",
+	// Greek
+	"α", "a", "β", "b", "ε", "e", "ι", "i", "κ", "k", "ν", "v", "ο", "o", "ρ", "p", "τ", "t", "υ", "u", "χ", "x",
+	// Latin that isn't decomposed
+	"ı", "i", "ƒ", "f", "ł", "l", "ø", "o", "đ", "d",
+)
+
+// Folds content so "ｆｒéе" (fullwidth, an accent and a Cyrillic е)
+// matches "free": compatibility decomposition, then combining marks and
+// invisible format characters (zero width spaces and joiners) are
+// dropped and lookalikes replaced.
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range capitalLookalikes.Replace(norm.NFKD.String(s)) {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return lookalikes.Replace(b.String())
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useBlocklist(t *testing.T, patterns string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte(patterns), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	blocklistMu.RLock()
+	saved := blocklist
+	blocklistMu.RUnlock()
+	if err := LoadBlocklist(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		blocklistMu.Lock()
+		blocklist = saved
+		blocklistMu.Unlock()
+	})
+}
+
+func TestBlockedEvasion(t *testing.T) {
+	useBlocklist(t, "# test list\nfree money\ns*x\n/\\bspam\\b/\ncafé\n")
+	for _, tt := range []struct {
+		name, content string
+	}{
+		{"plain", "get FREE MONEY now"},
+		{"fullwidth", "ｆｒｅｅ ｍｏｎｅｙ"},
+		{"cyrillic е and о", "frее mоney"},
+		{"greek capitals", "FRΕΕ MΟΝΕΥ"},
+		{"math bold", "𝐟𝐫𝐞𝐞 𝐦𝐨𝐧𝐞𝐲"},
+		{"circled", "ⓕⓡⓔⓔ ⓜⓞⓝⓔⓨ"},
+		{"zero width space", "fr​ee mon‍ey"},
+		{"combining accents", "fréë money"},
+		{"precomposed accents", "frée mönéy"},
+		{"glob through lookalikes", "ѕех"},
+		{"regex through lookalikes", "buy ѕрам here"},
+		{"pattern accent, NFD content", "un café noir"},
+		{"pattern accent, capitals", "CAFÉ"},
+		{"pattern accent, fullwidth", "ｃａｆé"},
+	} {
+		if !Blocked(tt.content) {
+			t.Errorf("%s: %q not blocked", tt.name, tt.content)
+		}
+	}
+}
+
+func TestBlockedLeavesOthers(t *testing.T) {
+	useBlocklist(t, "free money\ns*x\n/\\bspam\\b/\n")
+	for _, content := range []string{
+		"free time, no money",
+		"sit next",         // s*x stays within a word
+		"spammer",          // the regex wants word boundaries
+		"日本語のメッセージ",        // folding leaves other scripts alone
+		"привет, как дела", // and doesn't turn Russian into a match
+		"",
+	} {
+		if Blocked(content) {
+			t.Errorf("%q blocked", content)
+		}
+	}
+}
+
+func TestBlocklistSkipsInvalid(t *testing.T) {
+	useBlocklist(t, "/(unclosed/\nok\n")
+	if !Blocked("ok then") {
+		t.Error("valid pattern after an invalid one not loaded")
+	}
+	if Blocked("(unclosed") {
+		t.Error("invalid regex matched")
+	}
+}
+
+func TestBlocklistMissingFile(t *testing.T) {
+	useBlocklist(t, "free\n")
+	if err := LoadBlocklist(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Fatal(err)
+	}
+	if Blocked("free") {
+		t.Error("missing file should clear the list")
+	}
+}
+
+func TestFold(t *testing.T) {
+	for in, want := range map[string]string{
+		"ｆｒéе": "free",
+		"ﬁnd":  "find",
+		"Ǆ":    "dz",
+		"x​y":  "xy",
+		"ΑΒΓ":  "abγ",
+	} {
+		if got := fold(in); got != want {
+			t.Errorf("fold(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
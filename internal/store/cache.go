@@ -0,0 +1,137 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Parsed projects shared by every session, swapped whole on reload so
+// existing sessions keep the snapshot they started with.
+var (
+	cacheMu  sync.RWMutex
+	cached   []Project
+	cacheErr error
+	loaded   bool
+)
+
+// Cached projects, parsed on first use.
+func Projects() ([]Project, error) {
+	cacheMu.RLock()
+	if loaded {
+		defer cacheMu.RUnlock()
+		return cached, cacheErr
+	}
+	cacheMu.RUnlock()
+
+	_ = Reload()
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return cached, cacheErr
+}
+
+// Re-parses projects.txt and merges in GitHub projects if configured.
+// On error the previous good list is kept. A missing projects.txt isn't
+// an error, there are just no projects (besides GitHub's) until it's
+// created.
+func Reload() error {
+	projects, err := LoadProjects()
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Warn("No projects file, the projects page is empty until it's created", "file", projectsFile)
+		projects, err = nil, nil
+	}
+	if err == nil {
+		remote, ghErr := githubProjects()
+		if ghErr != nil {
+			log.Error("Could not load GitHub projects", "error", ghErr)
+		}
+		projects = sortProjects(mergeGitHub(projects, remote))
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if err != nil {
+		if loaded && cacheErr == nil {
+			log.Error("Reloading projects failed, keeping previous list", "error", err)
+			return err
+		}
+		cached, cacheErr, loaded = nil, err, true
+		return err
+	}
+	if loaded && cacheErr == nil {
+		added, removed, changed := diffProjects(cached, projects)
+		log.Info("Reloaded projects", "count", len(projects), "added", added, "removed", removed, "changed", changed)
+	} else {
+		log.Info("Loaded projects", "count", len(projects))
+	}
+	cached, cacheErr, loaded = projects, nil, true
+	return nil
+}
+
+// How many projects are in next but not prev, the other way round, and
+// in both but edited. A project is known by its number and title.
+func diffProjects(prev, next []Project) (added, removed, changed int) {
+	key := func(p Project) string { return fmt.Sprintf("%d %s", p.ProjectNumber, p.ProjectTitle) }
+	old := map[string]Project{}
+	for _, p := range prev {
+		old[key(p)] = p
+	}
+	for _, p := range next {
+		o, ok := old[key(p)]
+		switch {
+		case !ok:
+			added++
+		case o.Detail() != p.Detail():
+			changed++
+		}
+		delete(old, key(p))
+	}
+	return added, len(old), changed
+}
+
+// Reloads whenever projects.txt changes. The directory is watched
+// rather than the file so editors that replace it are picked up.
+func Watch(done <-chan struct{}) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.Add(filepath.Dir(projectsFile)); err != nil {
+		w.Close()
+		return err
+	}
+
+	go func() {
+		defer w.Close()
+		// Editors fire a burst of events per save, reload once it settles.
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) == filepath.Clean(projectsFile) {
+					debounce = time.After(200 * time.Millisecond)
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				log.Error("Watching projects failed", "error", err)
+			case <-debounce:
+				debounce = nil
+				_ = Reload()
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Daily rotation is the same for every visitor on a given day: the date
+// in the configured timezone hashed with a server salt.
+var (
+	dailyMu   sync.RWMutex
+	dailyLoc  = time.UTC
+	dailySalt string
+)
+
+func ConfigureDaily(loc *time.Location, salt string) {
+	dailyMu.Lock()
+	defer dailyMu.Unlock()
+	if loc != nil {
+		dailyLoc = loc
+	}
+	dailySalt = salt
+}
+
+// Seed for the day t falls on, key separates features so they
+// don't rotate in lockstep.
+func DailySeed(t time.Time, key string) uint64 {
+	dailyMu.RLock()
+	loc, salt := dailyLoc, dailySalt
+	dailyMu.RUnlock()
+
+	sum := sha256.Sum256([]byte(salt + "|" + key + "|" + t.In(loc).Format(DateLayout)))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Index in [0, n) for today, -1 if n is 0.
+func DailyIndex(t time.Time, key string, n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return int(DailySeed(t, key) % uint64(n))
+}
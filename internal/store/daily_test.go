@@ -0,0 +1,89 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+// Sets the daily timezone and salt for a test.
+func useDaily(t *testing.T, loc *time.Location, salt string) {
+	t.Helper()
+	dailyMu.RLock()
+	savedLoc, savedSalt := dailyLoc, dailySalt
+	dailyMu.RUnlock()
+	ConfigureDaily(loc, salt)
+	t.Cleanup(func() { ConfigureDaily(savedLoc, savedSalt) })
+}
+
+// Pinned so a change to the hashing, which would reshuffle every
+// visitor's day, shows up here first.
+func TestDailyPinned(t *testing.T) {
+	useDaily(t, time.UTC, "test-salt")
+	day := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	if got, want := DailySeed(day, "quote"), uint64(17560931917481532460); got != want {
+		t.Errorf("DailySeed = %d, want %d", got, want)
+	}
+	for _, tc := range []struct {
+		key  string
+		n    int
+		t    time.Time
+		want int
+	}{
+		{"quote", 7, day, 6},
+		{"featured-project", 9, day, 2},
+		{"quote", 7, day.AddDate(0, 0, 1), 5},
+		{"quote", 0, day, -1},
+	} {
+		if got := DailyIndex(tc.t, tc.key, tc.n); got != tc.want {
+			t.Errorf("DailyIndex(%s, %q, %d) = %d, want %d", tc.t.Format(DateLayout), tc.key, tc.n, got, tc.want)
+		}
+	}
+	if got, want := DailyQuote(day), quotes[6]; got != want {
+		t.Errorf("DailyQuote = %q, want %q", got, want)
+	}
+
+	// The salt is what makes one server's day differ from another's.
+	useDaily(t, time.UTC, "")
+	if got, want := DailySeed(day, "quote"), uint64(12525118698614049090); got != want {
+		t.Errorf("unsalted DailySeed = %d, want %d", got, want)
+	}
+}
+
+func TestDailySameAllDay(t *testing.T) {
+	useDaily(t, time.UTC, "test-salt")
+	start := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	want := DailyIndex(start, "quote", len(quotes))
+	for h := range 24 {
+		if got := DailyIndex(start.Add(time.Duration(h)*time.Hour+59*time.Minute), "quote", len(quotes)); got != want {
+			t.Fatalf("quote changed to %d at %02d:59, want %d", got, h, want)
+		}
+	}
+}
+
+// The day turns over at midnight in -daily-tz, not in UTC.
+func TestDailyFlipsAtLocalMidnight(t *testing.T) {
+	useDaily(t, time.FixedZone("UTC-5", -5*60*60), "test-salt")
+	before := time.Date(2026, 3, 15, 4, 59, 59, 0, time.UTC) // 23:59:59 on the 14th
+	after := before.Add(time.Second)
+	if got := DailyIndex(before, "quote", 7); got != 6 {
+		t.Errorf("before local midnight: %d, want the 14th's 6", got)
+	}
+	if got := DailyIndex(after, "quote", 7); got != 5 {
+		t.Errorf("after local midnight: %d, want the 15th's 5", got)
+	}
+	// Already the 15th in UTC, so UTC would have flipped hours earlier.
+	if got := DailyIndex(time.Date(2026, 3, 15, 1, 0, 0, 0, time.UTC), "quote", 7); got != 6 {
+		t.Errorf("01:00 UTC: %d, want still the 14th's 6", got)
+	}
+}
+
+func TestConfigureDailyKeepsZone(t *testing.T) {
+	zone := time.FixedZone("UTC+9", 9*60*60)
+	useDaily(t, zone, "a")
+	ConfigureDaily(nil, "b")
+	dailyMu.RLock()
+	defer dailyMu.RUnlock()
+	if dailyLoc != zone || dailySalt != "b" {
+		t.Errorf("after ConfigureDaily(nil): %v %q", dailyLoc, dailySalt)
+	}
+}
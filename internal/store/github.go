@@ -0,0 +1,224 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	githubAPI         = "https://api.github.com"
+	githubMaxRepos    = 10
+	readmeExcerptSize = 600
+)
+
+type GitHubConfig struct {
+	User      string
+	Token     string // optional, raises the rate limit
+	CachePath string
+	TTL       time.Duration
+}
+
+var (
+	githubMu  sync.RWMutex
+	githubCfg GitHubConfig
+)
+
+var githubClient = &http.Client{Timeout: 15 * time.Second}
+
+// Enables merging the user's top GitHub repos into the project list.
+func ConfigureGitHub(cfg GitHubConfig) {
+	githubMu.Lock()
+	defer githubMu.Unlock()
+	githubCfg = cfg
+}
+
+type githubCache struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Projects  []Project `json:"projects"`
+}
+
+// GitHub projects from the disk cache, refetched once it's older than
+// the TTL. A failed fetch falls back to the stale cache.
+func githubProjects() ([]Project, error) {
+	githubMu.RLock()
+	cfg := githubCfg
+	githubMu.RUnlock()
+	if cfg.User == "" {
+		return nil, nil
+	}
+
+	cache, cacheErr := readGitHubCache(cfg.CachePath)
+	if cacheErr == nil && time.Since(cache.FetchedAt) < cfg.TTL {
+		return cache.Projects, nil
+	}
+
+	projects, err := fetchGitHub(cfg)
+	if err != nil {
+		if cacheErr == nil {
+			log.Warn("GitHub fetch failed, using cached projects", "error", err, "fetched_at", cache.FetchedAt)
+			return cache.Projects, nil
+		}
+		return nil, err
+	}
+	if err := writeGitHubCache(cfg.CachePath, githubCache{FetchedAt: time.Now(), Projects: projects}); err != nil {
+		log.Warn("Could not write GitHub cache", "error", err)
+	}
+	log.Info("Fetched GitHub projects", "user", cfg.User, "count", len(projects))
+	return projects, nil
+}
+
+type githubRepo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	HTMLURL     string    `json:"html_url"`
+	Stars       int       `json:"stargazers_count"`
+	Fork        bool      `json:"fork"`
+	Topics      []string  `json:"topics"`
+	PushedAt    time.Time `json:"pushed_at"`
+}
+
+func fetchGitHub(cfg GitHubConfig) ([]Project, error) {
+	var repos []githubRepo
+	if err := githubGet(cfg, "/users/"+cfg.User+"/repos?per_page=100&type=owner", "application/vnd.github+json", &repos); err != nil {
+		return nil, err
+	}
+
+	// Top repos by stars, forks don't count.
+	var own []githubRepo
+	for _, r := range repos {
+		if !r.Fork {
+			own = append(own, r)
+		}
+	}
+	sort.SliceStable(own, func(i, j int) bool { return own[i].Stars > own[j].Stars })
+	if len(own) > githubMaxRepos {
+		own = own[:githubMaxRepos]
+	}
+
+	projects := make([]Project, 0, len(own))
+	for _, r := range own {
+		body := r.Description
+		var readme string
+		if err := githubGet(cfg, "/repos/"+cfg.User+"/"+r.Name+"/readme", "application/vnd.github.raw", &readme); err == nil {
+			body = strings.TrimSpace(body + "\n\n" + excerpt(readme, readmeExcerptSize))
+		}
+		projects = append(projects, Project{
+			ProjectTitle:   r.Name,
+			ProjectContent: body,
+			ProjectURL:     r.HTMLURL,
+			ProjectDate:    r.PushedAt,
+			ProjectTags:    r.Topics,
+			ProjectStars:   r.Stars,
+		})
+	}
+	return projects, nil
+}
+
+// Decodes JSON into out, or stores the raw body when out is a *string.
+func githubGet(cfg GitHubConfig, path, accept string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, githubAPI+path, nil) //nolint:noctx
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", accept)
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	resp, err := githubClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github: GET %s returned %d", path, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if s, ok := out.(*string); ok {
+		*s = string(data)
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// First paragraphs of a README up to n bytes, skipping headings and badges.
+func excerpt(readme string, n int) string {
+	var lines []string
+	size := 0
+	for _, line := range strings.Split(readme, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "![") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "<") {
+			continue
+		}
+		if size+len(line) > n {
+			break
+		}
+		lines = append(lines, line)
+		size += len(line) + 1
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func readGitHubCache(path string) (githubCache, error) {
+	var c githubCache
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+func writeGitHubCache(path string, c githubCache) error {
+	if path == "" {
+		return errors.New("no cache path")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Local projects win title collisions, GitHub ones are numbered after
+// the highest local number so existing shortcuts don't move.
+func mergeGitHub(local, remote []Project) []Project {
+	titles := map[string]bool{}
+	next := 0
+	for _, p := range local {
+		titles[strings.ToLower(p.ProjectTitle)] = true
+		next = max(next, p.ProjectNumber+1)
+	}
+
+	merged := append([]Project(nil), local...)
+	for _, p := range remote {
+		if titles[strings.ToLower(p.ProjectTitle)] {
+			continue
+		}
+		p.ProjectNumber = next
+		next++
+		merged = append(merged, p)
+	}
+	return merged
+}
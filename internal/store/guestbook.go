@@ -0,0 +1,198 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// Entries kept in the guestbook, the oldest go first.
+const GuestbookCap = 100
+
+var ErrMessageSpam = errors.New("links aren't allowed in the guestbook")
+
+// Anything that looks like a link or a bare domain.
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|\b[a-z0-9-]+\.(com|net|org|io|xyz|ru|info|biz|top|co)\b)`)
+
+// Messages their senders made public, oldest first. Kept apart from the
+// print queue so they outlive printing, and saved without the sender's
+// address or key.
+var (
+	guestbookMu    sync.Mutex
+	guestbook      []Message
+	guestbookPath  string
+	guestbookDirty = make(chan struct{}, 1)
+	guestbookWrite sync.Mutex
+)
+
+// Loads the guestbook from path and starts the goroutine that writes it
+// back. A missing file starts empty.
+func LoadGuestbook(path string) error {
+	guestbookPath = path
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		log.Info("No guestbook file, starting empty", "path", path)
+	case err != nil:
+		return err
+	default:
+		var loaded []Message
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return err
+		}
+		guestbookMu.Lock()
+		guestbook = loaded
+		guestbookMu.Unlock()
+	}
+
+	go func() {
+		for range guestbookDirty {
+			SaveGuestbook()
+		}
+	}()
+	return nil
+}
+
+// Adds a stored message to the guestbook, where it waits for approval.
+// Links are refused on top of the checks every message gets, the public
+// list would be the only place they're worth anything.
+func Publish(msg Message) (Message, error) {
+	if linkPattern.MatchString(msg.Content) || linkPattern.MatchString(msg.From) {
+		log.Warn("Not publishing message with a link", "from", msg.From, "remote", msg.RemoteAddr)
+		return msg, ErrMessageSpam
+	}
+	msg.Public = true
+	// Looks the same to the sender, it just never reaches the guestbook.
+	if msg.Quarantined != "" {
+		return msg, nil
+	}
+
+	messagesMu.Lock()
+	for i := range messages {
+		if messages[i].ID == msg.ID {
+			messages[i].Public = true
+		}
+	}
+	messagesMu.Unlock()
+
+	entry := Message{ID: msg.ID, From: msg.From, Content: msg.Content, Timestamp: msg.Timestamp, Public: true}
+	guestbookMu.Lock()
+	guestbook = append(guestbook, entry)
+	if len(guestbook) > GuestbookCap {
+		guestbook = append([]Message(nil), guestbook[len(guestbook)-GuestbookCap:]...)
+	}
+	guestbookMu.Unlock()
+	guestbookChanged()
+	return msg, nil
+}
+
+// Sets whether a public msg shows in the guestbook, false if it isn't in
+// there (private, or already pushed out by newer entries).
+func ApproveMessage(msg Message, approved bool) bool {
+	found := false
+	guestbookMu.Lock()
+	for i := range guestbook {
+		if guestbook[i].ID == msg.ID {
+			guestbook[i].Approved = approved
+			found = true
+		}
+	}
+	guestbookMu.Unlock()
+	if !found {
+		return false
+	}
+
+	messagesMu.Lock()
+	for i := range messages {
+		if messages[i].ID == msg.ID {
+			messages[i].Approved = approved
+		}
+	}
+	messagesMu.Unlock()
+	guestbookChanged()
+	return true
+}
+
+// Guestbook entries waiting for approval, oldest first. The printer may
+// have taken their messages off the queue long ago, so the inbox lists
+// these too.
+func PendingGuestbook() []Message {
+	guestbookMu.Lock()
+	defer guestbookMu.Unlock()
+	var out []Message
+	for _, e := range guestbook {
+		if !e.Approved {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Removes msg's guestbook entry, false if it has none.
+func DeleteGuestbookEntry(msg Message) bool {
+	found := false
+	guestbookMu.Lock()
+	for i := range guestbook {
+		if guestbook[i].ID == msg.ID {
+			guestbook = append(guestbook[:i], guestbook[i+1:]...)
+			found = true
+			break
+		}
+	}
+	guestbookMu.Unlock()
+	if found {
+		guestbookChanged()
+	}
+	return found
+}
+
+// Up to n approved guestbook entries, newest first.
+func Guestbook(n int) []Message {
+	guestbookMu.Lock()
+	defer guestbookMu.Unlock()
+	var out []Message
+	for i := len(guestbook) - 1; i >= 0 && len(out) < n; i-- {
+		if guestbook[i].Approved {
+			out = append(out, guestbook[i])
+		}
+	}
+	return out
+}
+
+func guestbookChanged() {
+	select {
+	case guestbookDirty <- struct{}{}:
+	default:
+	}
+}
+
+// Writes the guestbook through a temp file, like SavePrefs.
+func SaveGuestbook() {
+	if guestbookPath == "" {
+		return
+	}
+	guestbookWrite.Lock()
+	defer guestbookWrite.Unlock()
+
+	guestbookMu.Lock()
+	data, err := json.Marshal(guestbook)
+	guestbookMu.Unlock()
+	if err != nil {
+		log.Error("Could not encode guestbook", "error", err)
+		return
+	}
+	tmp := filepath.Join(filepath.Dir(guestbookPath), "."+filepath.Base(guestbookPath)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Error("Could not save guestbook", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, guestbookPath); err != nil {
+		log.Error("Could not save guestbook", "error", err)
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import "testing"
+
+func resetGuestbook(t *testing.T) {
+	t.Helper()
+	guestbookMu.Lock()
+	saved, savedPath := guestbook, guestbookPath
+	guestbook, guestbookPath = nil, ""
+	guestbookMu.Unlock()
+	t.Cleanup(func() {
+		guestbookMu.Lock()
+		guestbook, guestbookPath = saved, savedPath
+		guestbookMu.Unlock()
+	})
+}
+
+// The printer takes public messages off the queue like any other, they
+// still wait in the guestbook to be approved.
+func TestApproveAfterPrinted(t *testing.T) {
+	setQueue(t, RejectNew, 1000, 1<<20)
+	resetGuestbook(t)
+	msg, err := AddMessage("w", "lovely site", "192.0.2.1:1", "SHA256:abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, err = Publish(msg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := TakeFirst(); !ok {
+		t.Fatal("nothing for the printer")
+	}
+	if len(Messages()) != 0 {
+		t.Fatal("printed message still queued")
+	}
+
+	pending := PendingGuestbook()
+	if len(pending) != 1 || pending[0].ID != msg.ID {
+		t.Fatalf("pending %v, want the printed message", pending)
+	}
+	if pending[0].RemoteAddr != "" || pending[0].Fingerprint != "" {
+		t.Errorf("guestbook entry kept the sender's address or key: %+v", pending[0])
+	}
+	if !ApproveMessage(pending[0], true) {
+		t.Fatal("couldn't approve the printed message")
+	}
+	if got := Guestbook(10); len(got) != 1 || got[0].ID != msg.ID {
+		t.Errorf("guestbook %v, want the approved message", got)
+	}
+	if got := PendingGuestbook(); len(got) != 0 {
+		t.Errorf("still pending after approval: %v", got)
+	}
+}
+
+func TestDeleteGuestbookEntry(t *testing.T) {
+	setQueue(t, RejectNew, 1000, 1<<20)
+	resetGuestbook(t)
+	var published []Message
+	for _, content := range []string{"first entry", "second entry"} {
+		msg, err := AddMessage("w", content, "192.0.2.1:1", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg, err = Publish(msg); err != nil {
+			t.Fatal(err)
+		}
+		published = append(published, msg)
+	}
+	if !DeleteGuestbookEntry(published[0]) {
+		t.Fatal("entry not deleted")
+	}
+	if DeleteGuestbookEntry(published[0]) {
+		t.Error("entry deleted twice")
+	}
+	if got := PendingGuestbook(); len(got) != 1 || got[0].ID != published[1].ID {
+		t.Errorf("pending %v, want only the second entry", got)
+	}
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Messages one IP can leave per MessageWindow, over SSH and HTTP
+// together. 0 disables the limit.
+var (
+	MessageRate   = 5
+	MessageWindow = 10 * time.Minute
+)
+
+var messageLimiter = newLimiter()
+
+// Sliding window of recent events per key.
+type limiter struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	events map[string][]time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{now: time.Now, events: map[string][]time.Time{}}
+}
+
+// Records an event for key unless it already had rate within window.
+func (l *limiter) allow(key string, rate int, window time.Duration) bool {
+	if rate <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	// Forget keys that have gone quiet so the map doesn't grow forever.
+	if len(l.events) > 1024 {
+		for k, ts := range l.events {
+			if len(ts) == 0 || now.Sub(ts[len(ts)-1]) >= window {
+				delete(l.events, k)
+			}
+		}
+	}
+
+	recent := l.events[key][:0]
+	for _, t := range l.events[key] {
+		if now.Sub(t) < window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= rate {
+		l.events[key] = recent
+		return false
+	}
+	l.events[key] = append(recent, now)
+	return true
+}
+
+// IP part of a host:port address, the address itself if it has no port.
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
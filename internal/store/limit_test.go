@@ -0,0 +1,116 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// A limiter whose clock only moves when the test says so.
+func newTestLimiter() (*limiter, *time.Time) {
+	l := newLimiter()
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiterSlidingWindow(t *testing.T) {
+	l, now := newTestLimiter()
+	for i := range 3 {
+		if !l.allow("a", 3, time.Minute) {
+			t.Fatalf("event %d refused under the rate", i+1)
+		}
+		*now = now.Add(10 * time.Second)
+	}
+	if l.allow("a", 3, time.Minute) {
+		t.Fatal("fourth event in the window allowed")
+	}
+	if !l.allow("b", 3, time.Minute) {
+		t.Error("another key limited by a's events")
+	}
+
+	// The first event was at 0s, it's a minute old at 60s and no longer
+	// counts, the others at 10s and 20s still do.
+	*now = now.Add(30 * time.Second)
+	if !l.allow("a", 3, time.Minute) {
+		t.Fatal("refused once the oldest event left the window")
+	}
+	if l.allow("a", 3, time.Minute) {
+		t.Error("allowed a second time when only one slot freed up")
+	}
+}
+
+// Refused events don't extend the wait.
+func TestLimiterRefusalsDontCount(t *testing.T) {
+	l, now := newTestLimiter()
+	l.allow("a", 1, time.Minute)
+	for range 10 {
+		*now = now.Add(5 * time.Second)
+		if l.allow("a", 1, time.Minute) {
+			t.Fatal("allowed inside the window")
+		}
+	}
+	*now = now.Add(10 * time.Second)
+	if !l.allow("a", 1, time.Minute) {
+		t.Error("still refused a minute after the only allowed event")
+	}
+}
+
+func TestLimiterZeroRateDisables(t *testing.T) {
+	l, _ := newTestLimiter()
+	for range 100 {
+		if !l.allow("a", 0, time.Minute) {
+			t.Fatal("rate 0 refused")
+		}
+	}
+	if len(l.events) != 0 {
+		t.Error("rate 0 recorded events")
+	}
+}
+
+func TestLimiterForgetsQuietKeys(t *testing.T) {
+	l, now := newTestLimiter()
+	for i := range 1025 {
+		l.allow(fmt.Sprint("old", i), 1, time.Minute)
+	}
+	*now = now.Add(time.Minute)
+	l.allow("new", 1, time.Minute)
+	if len(l.events) != 1 {
+		t.Errorf("%d keys kept, want only the new one", len(l.events))
+	}
+}
+
+func TestMessageRateAcrossAddresses(t *testing.T) {
+	setQueue(t, RejectNew, 1000, 1<<20)
+	MessageRate = 2
+	l, now := newTestLimiter()
+	messageLimiter = l
+
+	// Same IP on different ports is the same sender.
+	for i, addr := range []string{"192.0.2.1:1", "192.0.2.1:2"} {
+		if _, err := AddMessage("w", fmt.Sprint("rate ", i), addr, ""); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+	}
+	if _, err := AddMessage("w", "rate 2", "192.0.2.1:3", ""); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("third message: %v, want ErrRateLimited", err)
+	}
+	*now = now.Add(MessageWindow)
+	if _, err := AddMessage("w", "rate 3", "192.0.2.1:3", ""); err != nil {
+		t.Errorf("after MessageWindow: %v", err)
+	}
+}
+
+func TestHostOf(t *testing.T) {
+	for in, want := range map[string]string{
+		"192.0.2.1:22":     "192.0.2.1",
+		"[2001:db8::1]:22": "2001:db8::1",
+		"192.0.2.1":        "192.0.2.1",
+		"":                 "",
+	} {
+		if got := hostOf(in); got != want {
+			t.Errorf("hostOf(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/metrics"
+)
+
+// Longest message in runes we'll store or hand to the printer.
+var MaxMessageLength = 500
+
+// Longest sender name in runes.
+const MaxNameLength = 40
+
+var (
+	ErrMessageTooLong = errors.New("message too long")
+	ErrMessageEmpty   = errors.New("message is empty")
+	ErrNameTooLong    = errors.New("name too long")
+	ErrUnprintable    = errors.New("message or name has control characters")
+	ErrRateLimited    = errors.New("too many messages, try again later")
+	ErrMailboxFull    = errors.New("the mailbox is full, try again later")
+)
+
+// Most newlines a message can have, paper's expensive.
+const maxMessageNewlines = 9
+
+// Unscraped until UseMetrics swaps in the real registry.
+var stats = metrics.New(metrics.NewRegistry())
+
+func UseMetrics(m *metrics.Metrics) {
+	stats = m
+}
+
+// Takes the oldest message off the queue, under one lock so two printers
+// fetching at once don't both get it.
+func TakeFirst() (Message, bool) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	if len(messages) == 0 {
+		return Message{}, false
+	}
+	first := messages[0]
+	messages = append(messages[:0], messages[1:]...)
+	queued.Store(int64(len(messages)))
+	return first, true
+}
+
+type Message struct {
+	ID          string    `json:"id"`
+	From        string    `json:"from"`
+	Content     string    `json:"content"`
+	Timestamp   time.Time `json:"timestamp"`
+	RemoteAddr  string    `json:"remote_addr,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"` // sender's SSH key, empty for quiz logins
+	Public      bool      `json:"public,omitempty"`      // sender asked for it in the guestbook
+	Approved    bool      `json:"approved,omitempty"`    // shown in the guestbook
+	Quarantined string    `json:"quarantined,omitempty"` // why the filters held it back, see filterReason
+}
+
+var (
+	messages   []Message
+	messagesMu sync.RWMutex
+	queued     atomic.Int64 // len(messages), readable without the lock
+)
+
+// The checks every message gets before it's stored, whichever way it
+// was sent. The spam filters come after, in AddMessage. Control
+// characters are refused outright, they'd otherwise reach the inbox and
+// guestbook as escape sequences for whoever reads them.
+func Validate(from, content string) error {
+	switch {
+	case strings.TrimSpace(content) == "":
+		return ErrMessageEmpty
+	case utf8.RuneCountInString(content) > MaxMessageLength || strings.Count(content, "\n") > maxMessageNewlines:
+		return ErrMessageTooLong
+	case utf8.RuneCountInString(from) > MaxNameLength:
+		return ErrNameTooLong
+	case !printable(from, false) || !printable(content, true):
+		return ErrUnprintable
+	}
+	return nil
+}
+
+// Whether s is valid UTF-8 without C0 or C1 control characters, bar
+// newlines if newlines is set. Invalid bytes are refused too, 0x9b on
+// its own is a CSI to some terminals.
+func printable(s string, newlines bool) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r == '\n' && newlines {
+			continue
+		}
+		if isControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r >= 0x7f && r <= 0x9f
+}
+
+// name without control characters and cut to MaxNameLength, for names
+// that come from elsewhere, e.g. an SSH username.
+func CleanName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if isControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(name, ""))
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		name = string([]rune(name)[:MaxNameLength])
+	}
+	return strings.TrimSpace(name)
+}
+
+// remoteAddr and fingerprint identify the sender, kept for abuse handling.
+// Senders are limited to MessageRate messages per MessageWindow by IP.
+// Messages the filters catch are quarantined instead of queued, and
+// returned like any other so the sender is none the wiser.
+func AddMessage(from, content, remoteAddr, fingerprint string) (Message, error) {
+	if err := Validate(from, content); err != nil {
+		return Message{}, err
+	}
+	if QueuePolicy == RejectNew && queueFull(len(from)+len(content)) {
+		log.Warn("Message queue full, rejecting message", "max", MaxQueue, "max_bytes", MaxQueueBytes, "from", from, "remote", remoteAddr)
+		return Message{}, ErrMailboxFull
+	}
+	if !messageLimiter.allow(hostOf(remoteAddr), MessageRate, MessageWindow) {
+		return Message{}, ErrRateLimited
+	}
+	msg := Message{
+		ID:          newMessageID(),
+		From:        from,
+		Content:     content,
+		Timestamp:   time.Now(),
+		RemoteAddr:  remoteAddr,
+		Fingerprint: fingerprint,
+	}
+	if reason := filterReason(from, content, remoteAddr); reason != "" {
+		msg.Quarantined = reason
+		hold(msg)
+		return msg, nil
+	}
+	enqueue(msg)
+	return msg, nil
+}
+
+// Queues msg for the printer and hands it to the Worker, if there is one.
+// Past the queue's bounds the oldest messages go, see trimQueue.
+func enqueue(msg Message) {
+	messagesMu.Lock()
+	messages = append(messages, msg)
+	trimQueue()
+	queued.Store(int64(len(messages)))
+	close(arrived)
+	arrived = make(chan struct{})
+	messagesMu.Unlock()
+	notify(msg)
+
+	stats.MessagesSubmitted.Inc()
+	totalMessages.Add(1)
+	log.Info("New message saved", "from", msg.From, "content", msg.Content, "remote", msg.RemoteAddr, "fingerprint", msg.Fingerprint)
+
+	if workerURL != "" {
+		go postToWorker(msg)
+	}
+}
+
+// Last ID handed out, starting from the clock so IDs keep increasing
+// across restarts.
+var lastID atomic.Uint64
+
+func init() {
+	lastID.Store(uint64(time.Now().UnixNano()))
+}
+
+// Fixed width hex so IDs sort as strings in the order messages arrived,
+// see MessagesAfter.
+func newMessageID() string {
+	return fmt.Sprintf("%016x", lastID.Add(1))
+}
+
+// Every stored message, oldest first, without removing any.
+func Messages() []Message {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	msgCopy := make([]Message, len(messages))
+	copy(msgCopy, messages)
+	return msgCopy
+}
+
+// Messages waiting for the printer, without taking the lock.
+func Queued() int64 {
+	return queued.Load()
+}
+
+// msg's place in the print queue (1 is next), 0 once it's gone or when
+// the Worker holds the queue. A quarantined message gets the place it
+// would have had, like AddMessage it doesn't let on.
+func QueuePosition(msg Message) int {
+	if workerURL != "" {
+		return 0
+	}
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	if msg.Quarantined != "" {
+		return len(messages) + 1
+	}
+	for i := range messages {
+		if messages[i].ID == msg.ID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Removes msg from the store by ID, false if it had already gone (e.g.
+// printed).
+func DeleteMessage(msg Message) bool {
+	return DeleteByID(msg.ID)
+}
+
+// Queue summary for diagnostics, no message contents.
+type Stats struct {
+	Queued           int        `json:"queued"`
+	Oldest           *time.Time `json:"oldest,omitempty"`
+	WorkerConfigured bool       `json:"worker_configured"`
+	MaxMessageLength int        `json:"max_message_length"`
+}
+
+func QueueStats() Stats {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	st := Stats{
+		Queued:           len(messages),
+		WorkerConfigured: workerURL != "",
+		MaxMessageLength: MaxMessageLength,
+	}
+	if len(messages) > 0 {
+		oldest := messages[0].Timestamp
+		st.Oldest = &oldest
+	}
+	return st
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+// Empties the queue, limiter and duplicate filter for a test and puts
+// them back after.
+func resetMessages(t *testing.T) {
+	t.Helper()
+	recentMu.Lock()
+	savedRecent := recent
+	recent = nil
+	recentMu.Unlock()
+	messagesMu.Lock()
+	saved := messages
+	messages = nil
+	queued.Store(0)
+	messagesMu.Unlock()
+	savedLimiter := messageLimiter
+	messageLimiter = newLimiter()
+	t.Cleanup(func() {
+		messagesMu.Lock()
+		messages = saved
+		queued.Store(int64(len(saved)))
+		messagesMu.Unlock()
+		messageLimiter = savedLimiter
+		recentMu.Lock()
+		recent = savedRecent
+		recentMu.Unlock()
+	})
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		from, content string
+		want          error
+	}{
+		{"will", "hello\nthere", nil},
+		{"will", "tab\there", ErrUnprintable},
+		{"will", "bell\a", ErrUnprintable},
+		{"will", "del\x7f", ErrUnprintable},
+		{"will", "bad \xff utf-8", ErrUnprintable},
+		{"wi\nll", "hello", ErrUnprintable},
+		{"日本語", "こんにちは 👋", nil},
+	} {
+		if err := Validate(tt.from, tt.content); err != tt.want {
+			t.Errorf("Validate(%q, %q) = %v, want %v", tt.from, tt.content, err, tt.want)
+		}
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	for in, want := range map[string]string{
+		"will":                  "will",
+		"\x1b[31mred\x1b[0m":    "[31mred[0m",
+		" bad\xffbyte ":         "badbyte",
+		strings.Repeat("x", 50): strings.Repeat("x", MaxNameLength),
+	} {
+		if got := CleanName(in); got != want {
+			t.Errorf("CleanName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
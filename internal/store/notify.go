@@ -0,0 +1,100 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	notifyAttempts = 3
+	notifyQueueLen = 64
+)
+
+var (
+	notifyQueue chan Message
+	notifyDone  chan struct{} // closed once the worker has drained a closed queue
+)
+
+// Base delay between webhook retries, doubled each attempt.
+var notifyBackoff = time.Second
+
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// Starts the webhook worker, new messages are POSTed to url.
+func StartNotifier(url string) {
+	queue, done := make(chan Message, notifyQueueLen), make(chan struct{})
+	notifyQueue, notifyDone = queue, done
+	go func() {
+		defer close(done)
+		for msg := range queue {
+			sendNotification(url, msg)
+		}
+	}()
+}
+
+// Stops taking notifications and waits for the queued ones to be sent.
+func stopNotifier() {
+	if notifyQueue == nil {
+		return
+	}
+	close(notifyQueue)
+	<-notifyDone
+	notifyQueue = nil
+}
+
+// Never blocks, a full queue drops the notification.
+func notify(msg Message) {
+	if notifyQueue == nil {
+		return
+	}
+	select {
+	case notifyQueue <- msg:
+	default:
+		log.Warn("Notify: queue full, dropping notification", "from", msg.From)
+	}
+}
+
+func sendNotification(url string, msg Message) {
+	// "message" for ntfy, "content" doubles as the Discord body.
+	body, err := json.Marshal(map[string]string{
+		"from":      msg.From,
+		"content":   msg.Content,
+		"timestamp": msg.Timestamp.Format(time.RFC3339),
+		"message":   fmt.Sprintf("New message from %s: %s", msg.From, msg.Content),
+	})
+	if err != nil {
+		log.Errorf("Notify: failed to marshal message: %v", err)
+		return
+	}
+
+	delay := notifyBackoff
+	for attempt := 1; attempt <= notifyAttempts; attempt++ {
+		err = postNotification(url, body)
+		if err == nil {
+			return
+		}
+		log.Warn("Notify: webhook failed", "attempt", attempt, "error", err)
+		if attempt < notifyAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	log.Errorf("Notify: giving up after %d attempts: %v", notifyAttempts, err)
+}
+
+func postNotification(url string, body []byte) error {
+	resp, err := notifyClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Starts the notifier against h, stopping it when the test ends.
+func startNotifier(t *testing.T, h http.HandlerFunc) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	savedBackoff := notifyBackoff
+	notifyBackoff = time.Millisecond
+	StartNotifier(srv.URL)
+	t.Cleanup(func() {
+		stopNotifier()
+		srv.Close()
+		notifyBackoff = savedBackoff
+	})
+}
+
+// Captures the default logger's output for a test.
+func captureLog(t *testing.T) *logBuffer {
+	t.Helper()
+	buf := &logBuffer{}
+	log.SetOutput(buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return buf
+}
+
+// A buffer background goroutines can log to while the test reads it.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Polls cond until it holds or a second has passed.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestNotifyPayload(t *testing.T) {
+	resetMessages(t)
+	got := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	startNotifier(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- r
+		bodies <- body
+	})
+
+	msg, err := AddMessage("will", "hello webhook", "192.0.2.1:1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r *http.Request
+	select {
+	case r = <-got:
+	case <-time.After(time.Second):
+		t.Fatal("webhook not called")
+	}
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("got %s with %q", r.Method, r.Header.Get("Content-Type"))
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(<-bodies, &payload); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"from":      "will",
+		"content":   "hello webhook",
+		"timestamp": msg.Timestamp.Format(time.RFC3339),
+		"message":   "New message from will: hello webhook",
+	}
+	for k, v := range want {
+		if payload[k] != v {
+			t.Errorf("%s = %q, want %q", k, payload[k], v)
+		}
+	}
+	if len(payload) != len(want) {
+		t.Errorf("payload %v has extra fields", payload)
+	}
+}
+
+// A webhook that never answers holds up the worker, not the sender.
+func TestNotifyDoesNotBlockAddMessage(t *testing.T) {
+	resetMessages(t)
+	setQueue(t, RejectNew, 1000, 1<<20)
+	logs := captureLog(t)
+	release := make(chan struct{})
+	startNotifier(t, func(http.ResponseWriter, *http.Request) { <-release })
+	// Runs before the notifier stops, so the queued ones go through.
+	t.Cleanup(func() { close(release) })
+
+	start := time.Now()
+	fill(t, notifyQueueLen+10)
+	if took := time.Since(start); took > 500*time.Millisecond {
+		t.Errorf("AddMessage took %s with the webhook stuck", took)
+	}
+	if len(Messages()) != notifyQueueLen+10 {
+		t.Errorf("%d messages queued, want %d", len(Messages()), notifyQueueLen+10)
+	}
+	if !strings.Contains(logs.String(), "queue full, dropping notification") {
+		t.Error("overflowing notifications weren't logged")
+	}
+}
+
+func TestNotifyFailuresLogged(t *testing.T) {
+	resetMessages(t)
+	logs := captureLog(t)
+	calls := make(chan struct{}, notifyAttempts+1)
+	startNotifier(t, func(w http.ResponseWriter, _ *http.Request) {
+		calls <- struct{}{}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	if _, err := AddMessage("will", "this one fails", "192.0.2.1:1", ""); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the notifier to give up", func() bool {
+		return strings.Contains(logs.String(), "giving up after 3 attempts")
+	})
+	out := logs.String()
+	if n := strings.Count(out, "webhook failed"); n != notifyAttempts {
+		t.Errorf("%d failed attempts logged, want %d:\n%s", n, notifyAttempts, out)
+	}
+	if !strings.Contains(out, "webhook returned 502") {
+		t.Errorf("status not logged:\n%s", out)
+	}
+	if len(calls) != notifyAttempts {
+		t.Errorf("webhook called %d times, want %d", len(calls), notifyAttempts)
+	}
+}
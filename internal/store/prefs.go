@@ -0,0 +1,115 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// What a visitor with a key picked last time, restored when they return.
+type Prefs struct {
+	Name    string `json:"name,omitempty"`    // set with ctrl+n
+	Theme   string `json:"theme,omitempty"`   // Theme.Name
+	Section string `json:"section,omitempty"` // State.String()
+}
+
+// Keyed by a hash of the key fingerprint.
+var (
+	prefsMu   sync.Mutex
+	prefs     = map[string]Prefs{}
+	prefsPath string
+	// Wakes the writer, buffered so a burst of changes is one write.
+	prefsDirty  = make(chan struct{}, 1)
+	prefsWrite  sync.Mutex
+	prefsWriter sync.Once
+)
+
+func visitorID(visitor string) string {
+	sum := sha256.Sum256([]byte(visitor))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Loads saved prefs from path and starts the goroutine that owns writing
+// them back, only one however often this is called. A missing file
+// starts empty.
+func LoadPrefs(path string) error {
+	prefsMu.Lock()
+	prefsPath = path
+	prefsMu.Unlock()
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		log.Info("No saved preferences, starting empty", "path", path)
+	case err != nil:
+		return err
+	default:
+		loaded := map[string]Prefs{}
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return err
+		}
+		prefsMu.Lock()
+		prefs = loaded
+		prefsMu.Unlock()
+	}
+
+	prefsWriter.Do(func() {
+		go func() {
+			for range prefsDirty {
+				SavePrefs()
+			}
+		}()
+	})
+	return nil
+}
+
+func PrefsFor(fingerprint string) (Prefs, bool) {
+	prefsMu.Lock()
+	defer prefsMu.Unlock()
+	p, ok := prefs[visitorID(fingerprint)]
+	return p, ok
+}
+
+// Stores p for fingerprint, the file is written in the background.
+func SetPrefs(fingerprint string, p Prefs) {
+	prefsMu.Lock()
+	prefs[visitorID(fingerprint)] = p
+	prefsMu.Unlock()
+	select {
+	case prefsDirty <- struct{}{}:
+	default:
+	}
+}
+
+// Writes every visitor's prefs, one write at a time, through a temp file
+// so sessions saving together can't interleave or leave half a file.
+func SavePrefs() {
+	prefsWrite.Lock()
+	defer prefsWrite.Unlock()
+
+	prefsMu.Lock()
+	path := prefsPath
+	data, err := json.Marshal(prefs)
+	prefsMu.Unlock()
+	if path == "" {
+		return
+	}
+	if err != nil {
+		log.Error("Could not encode preferences", "error", err)
+		return
+	}
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Error("Could not save preferences", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		log.Error("Could not save preferences", "error", err)
+	}
+}
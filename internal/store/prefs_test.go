@@ -0,0 +1,143 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Points prefs at a fresh file for a test and starts its writer.
+func usePrefs(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prefs.json")
+	prefsMu.Lock()
+	saved, savedPath := prefs, prefsPath
+	prefs = map[string]Prefs{}
+	prefsMu.Unlock()
+	if err := LoadPrefs(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		prefsWrite.Lock()
+		defer prefsWrite.Unlock()
+		prefsMu.Lock()
+		defer prefsMu.Unlock()
+		prefs, prefsPath = saved, savedPath
+	})
+	return path
+}
+
+func readPrefsFile(t *testing.T, path string) map[string]Prefs {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := map[string]Prefs{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("prefs file isn't valid JSON: %v\n%s", err, data)
+	}
+	return saved
+}
+
+// Two sessions from the same key changing prefs at once, with another
+// key's session and the writer going, under -race. The file is whole
+// whenever it's read and ends with what was set last.
+func TestSetPrefsConcurrently(t *testing.T) {
+	path := usePrefs(t)
+	const rounds = 200
+
+	stop := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if data, err := os.ReadFile(path); err == nil {
+				var saved map[string]Prefs
+				if err := json.Unmarshal(data, &saved); err != nil {
+					t.Errorf("read a torn prefs file: %v", err)
+					return
+				}
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, session := range []struct{ key, name string }{
+		{"SHA256:same", "laptop"},
+		{"SHA256:same", "desktop"},
+		{"SHA256:other", "other"},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rounds {
+				SetPrefs(session.key, Prefs{Name: session.name, Theme: fmt.Sprint(i), Section: "home"})
+				if p, ok := PrefsFor(session.key); !ok || p.Section != "home" {
+					t.Errorf("%s: PrefsFor = %+v, %v", session.name, p, ok)
+				}
+				if i%50 == 0 {
+					SavePrefs()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-readerDone
+
+	SavePrefs()
+	saved := readPrefsFile(t, path)
+	if len(saved) != 2 {
+		t.Fatalf("%d visitors saved, want 2", len(saved))
+	}
+	same, _ := PrefsFor("SHA256:same")
+	if same.Name != "laptop" && same.Name != "desktop" || same.Theme != fmt.Sprint(rounds-1) {
+		t.Errorf("same key ended as %+v, want one session's last prefs", same)
+	}
+	if saved[visitorID("SHA256:same")] != same {
+		t.Errorf("file has %+v, memory %+v", saved[visitorID("SHA256:same")], same)
+	}
+	if other := saved[visitorID("SHA256:other")]; other.Name != "other" || other.Theme != fmt.Sprint(rounds-1) {
+		t.Errorf("other key saved as %+v", other)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), ".prefs.json.tmp")); !os.IsNotExist(err) {
+		t.Error("temp file left behind")
+	}
+}
+
+// Saved prefs come back on the next start, keyed so the file doesn't
+// hold fingerprints.
+func TestPrefsReload(t *testing.T) {
+	path := usePrefs(t)
+	SetPrefs("SHA256:abc", Prefs{Name: "will", Theme: "dracula", Section: "blog"})
+	SavePrefs()
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "SHA256:abc") {
+		t.Error("fingerprint written to the prefs file")
+	}
+
+	prefsMu.Lock()
+	prefs = map[string]Prefs{}
+	prefsMu.Unlock()
+	if err := LoadPrefs(path); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := PrefsFor("SHA256:abc"); !ok || p.Name != "will" || p.Section != "blog" {
+		t.Errorf("reloaded %+v, %v", p, ok)
+	}
+	if _, ok := PrefsFor("SHA256:unknown"); ok {
+		t.Error("prefs for a key never seen")
+	}
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const projectsFile = "projects.txt"
+
+// Layout of the optional Date: line in projects.txt
+const DateLayout = "2006-01-02"
+
+type Project struct {
+	ProjectTitle   string    `json:"title"`
+	ProjectContent string    `json:"content"`
+	ProjectNumber  int       `json:"number"`
+	ProjectURL     string    `json:"url,omitempty"`
+	ProjectDate    time.Time `json:"date,omitempty"`
+	ProjectTags    []string  `json:"tags,omitempty"`
+	ProjectStars   int       `json:"stars,omitempty"` // GitHub projects only
+}
+
+// bubbles/list.Item interface.
+func (p Project) Title() string { return fmt.Sprintf("%d. %s", p.ProjectNumber, p.ProjectTitle) }
+func (p Project) Description() string {
+	if len(p.ProjectTags) == 0 {
+		return p.Summary()
+	}
+	return p.Summary() + " " + p.TagList()
+}
+
+// The start of the write up and any stars, Description without the tags.
+func (p Project) Summary() string {
+	desc := p.ProjectContent
+	// Cut by cells, slicing bytes splits multibyte runes and miscounts
+	// wide ones.
+	if ansi.StringWidth(desc) > 100 {
+		desc = ansi.Truncate(desc, 100, "") + "..."
+	}
+	if p.ProjectStars > 0 {
+		desc = fmt.Sprintf("★ %d  %s", p.ProjectStars, desc)
+	}
+	return desc
+}
+
+// Tags as shown in the list, e.g. "[go, ssh]". Unstyled, styles have to
+// come from the session's renderer.
+func (p Project) TagList() string {
+	if len(p.ProjectTags) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(p.ProjectTags, ", ") + "]"
+}
+func (p Project) FilterValue() string {
+	return p.ProjectTitle + " " + strings.Join(p.ProjectTags, " ") + " " + p.ProjectContent
+}
+
+// Content shown in the detail viewport, metadata first.
+func (p Project) Detail() string {
+	var meta []string
+	if p.ProjectURL != "" {
+		meta = append(meta, "URL: "+p.ProjectURL)
+	}
+	if !p.ProjectDate.IsZero() {
+		meta = append(meta, "Date: "+p.ProjectDate.Format(DateLayout))
+	}
+	if len(p.ProjectTags) > 0 {
+		meta = append(meta, "Tags: "+strings.Join(p.ProjectTags, ", "))
+	}
+	if len(meta) == 0 {
+		return p.ProjectContent
+	}
+	return strings.Join(meta, "\n") + "\n\n" + p.ProjectContent
+}
+
+func LoadProjects() ([]Project, error) {
+	data, err := os.ReadFile(projectsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := strings.Split(string(data), "---")
+	var projects []Project
+
+	// Line in projectsFile the current block starts on, for warnings.
+	start := 1
+	for _, block := range blocks {
+		first := start + strings.Count(block[:len(block)-len(strings.TrimLeftFunc(block, unicode.IsSpace))], "\n")
+		start += strings.Count(block, "\n")
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		var p Project
+		var contentLines []string
+
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if t, found := strings.CutPrefix(line, "Title:"); found {
+				p.ProjectTitle = strings.TrimSpace(t)
+			} else if numStr, found := strings.CutPrefix(line, "Number:"); found {
+				num, _ := strconv.Atoi(strings.TrimSpace(numStr))
+				p.ProjectNumber = num
+			} else if u, found := strings.CutPrefix(line, "URL:"); found {
+				p.ProjectURL = strings.TrimSpace(u)
+			} else if d, found := strings.CutPrefix(line, "Date:"); found {
+				t, err := time.Parse(DateLayout, strings.TrimSpace(d))
+				if err != nil {
+					log.Warn("Ignoring project date, want YYYY-MM-DD", "file", projectsFile, "line", first+i, "text", line, "error", err)
+				}
+				p.ProjectDate = t
+			} else if tags, found := strings.CutPrefix(line, "Tags:"); found {
+				for _, tag := range strings.Split(tags, ",") {
+					if tag = strings.TrimSpace(tag); tag != "" {
+						p.ProjectTags = append(p.ProjectTags, tag)
+					}
+				}
+			} else if line != "" || i > 2 {
+				contentLines = append(contentLines, line)
+			}
+		}
+		p.ProjectContent = strings.TrimSpace(strings.Join(contentLines, "\n"))
+		if p.ProjectTitle != "" {
+			projects = append(projects, p)
+		}
+	}
+
+	projects = sortProjects(projects)
+	for i := 1; i < len(projects); i++ {
+		if projects[i].ProjectNumber == projects[i-1].ProjectNumber {
+			log.Warn("Duplicate project number", "number", projects[i].ProjectNumber,
+				"title", projects[i].ProjectTitle, "other", projects[i-1].ProjectTitle)
+		}
+	}
+
+	return projects, nil
+}
+
+// Index of the project with the given number, -1 if there isn't one.
+func FindByNumber(projects []Project, number int) int {
+	for i, p := range projects {
+		if p.ProjectNumber == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// Highest (newest) number first so the list matches the shortcuts,
+// same numbers fall back to newest date.
+func sortProjects(projects []Project) []Project {
+	sort.SliceStable(projects, func(i, j int) bool {
+		if projects[i].ProjectNumber != projects[j].ProjectNumber {
+			return projects[i].ProjectNumber > projects[j].ProjectNumber
+		}
+		return projects[i].ProjectDate.After(projects[j].ProjectDate)
+	})
+	return projects
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestDescriptionUnstyled(t *testing.T) {
+	p := Project{ProjectContent: "A tool", ProjectTags: []string{"go", "ssh"}, ProjectStars: 3}
+	if got, want := p.Description(), "★ 3  A tool [go, ssh]"; got != want {
+		t.Errorf("Description = %q, want %q", got, want)
+	}
+	if strings.Contains(p.Description(), "\x1b") {
+		t.Error("Description has escape sequences, styling is up to the session")
+	}
+	if got := (Project{ProjectContent: "No tags"}).Description(); got != "No tags" {
+		t.Errorf("Description without tags = %q", got)
+	}
+}
+
+func TestSummaryTruncatesByCells(t *testing.T) {
+	p := Project{ProjectContent: strings.Repeat("漢", 80)}
+	got := p.Summary()
+	if !strings.HasSuffix(got, "...") || strings.ContainsRune(got, '�') {
+		t.Errorf("Summary = %q, want it cut whole runes short of 100 cells", got)
+	}
+	if n := len([]rune(strings.TrimSuffix(got, "..."))); n != 50 {
+		t.Errorf("kept %d wide runes, want 50", n)
+	}
+}
+
+func TestBadDateWarnsWithLine(t *testing.T) {
+	t.Chdir(t.TempDir())
+	data := `---
+Title: Good
+Number: 2
+Date: 2025-01-02
+- fine
+---
+
+Title: Bad
+Number: 1
+Date: 02/01/2025
+- not fine
+`
+	if err := os.WriteFile(projectsFile, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	projects, err := LoadProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].ProjectDate.IsZero() || !projects[1].ProjectDate.IsZero() {
+		t.Fatalf("projects = %+v, want the good date kept and the bad one dropped", projects)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "line=10") || !strings.Contains(out, "02/01/2025") {
+		t.Errorf("warning = %q, want line 10 and the bad date", out)
+	}
+	if strings.Count(out, "Ignoring project date") != 1 {
+		t.Errorf("warning = %q, want one for the bad date only", out)
+	}
+}
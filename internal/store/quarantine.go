@@ -0,0 +1,148 @@
+package store
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+)
+
+// Earlier messages from the same session a new one is checked against
+// for exact duplicates.
+var DuplicateWindow = 5
+
+const (
+	// Messages held back at once, the oldest go first.
+	QuarantineCap = 200
+
+	// Share of a message's characters in links above which it's held back.
+	maxLinkShare = 0.8
+
+	// Sent messages remembered for DuplicateWindow, across every session.
+	recentCap = 1000
+)
+
+// A whole link, linkPattern only finds where one starts.
+var linkRun = regexp.MustCompile(`\S*(?:` + linkPattern.String() + `)\S*`)
+
+// Messages the filters caught, kept out of the print queue until they're
+// released from the inbox.
+var (
+	quarantine   []Message
+	quarantineMu sync.RWMutex
+)
+
+type recentMessage struct{ sender, content string }
+
+var (
+	recentMu sync.Mutex
+	recent   []recentMessage // oldest first
+)
+
+// Why a message shouldn't go to the printer, empty if it can. Caught
+// messages are quarantined rather than refused so a sender can't tell
+// which filter they hit, or that any did.
+func filterReason(from, content, sender string) string {
+	dup := repeated(sender, content)
+	switch {
+	case Blocked(content) || Blocked(from):
+		return "blocklist"
+	case mostlyLinks(content):
+		return "links"
+	case dup:
+		return "duplicate"
+	}
+	return ""
+}
+
+// Whether more than maxLinkShare of content, ignoring spaces, is links.
+func mostlyLinks(content string) bool {
+	total := 0
+	for _, r := range content {
+		if !unicode.IsSpace(r) {
+			total++
+		}
+	}
+	links := 0
+	for _, l := range linkRun.FindAllString(content, -1) {
+		links += utf8.RuneCountInString(l)
+	}
+	return total > 0 && float64(links) > maxLinkShare*float64(total)
+}
+
+// Whether sender sent content in their last DuplicateWindow messages,
+// remembering it either way.
+func repeated(sender, content string) bool {
+	content = strings.TrimSpace(content)
+	recentMu.Lock()
+	defer recentMu.Unlock()
+	dup := false
+	for i, n := len(recent)-1, 0; i >= 0 && n < DuplicateWindow; i-- {
+		if recent[i].sender != sender {
+			continue
+		}
+		n++
+		if recent[i].content == content {
+			dup = true
+			break
+		}
+	}
+	recent = append(recent, recentMessage{sender, content})
+	if len(recent) > recentCap {
+		recent = recent[len(recent)-recentCap:]
+	}
+	return dup
+}
+
+func hold(msg Message) {
+	quarantineMu.Lock()
+	quarantine = append(quarantine, msg)
+	if len(quarantine) > QuarantineCap {
+		quarantine = append([]Message(nil), quarantine[len(quarantine)-QuarantineCap:]...)
+	}
+	quarantineMu.Unlock()
+	log.Warn("Message quarantined", "reason", msg.Quarantined, "from", msg.From, "remote", msg.RemoteAddr, "fingerprint", msg.Fingerprint)
+}
+
+// Every quarantined message, oldest first.
+func Quarantine() []Message {
+	quarantineMu.RLock()
+	defer quarantineMu.RUnlock()
+	return append([]Message(nil), quarantine...)
+}
+
+func takeQuarantined(msg Message) (Message, bool) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+	for i := range quarantine {
+		if quarantine[i].ID == msg.ID {
+			held := quarantine[i]
+			quarantine = append(quarantine[:i], quarantine[i+1:]...)
+			return held, true
+		}
+	}
+	return Message{}, false
+}
+
+// Drops a quarantined message, false if it had already gone.
+func DeleteQuarantined(msg Message) bool {
+	_, ok := takeQuarantined(msg)
+	return ok
+}
+
+// Sends a quarantined message on to the print queue after all.
+func ReleaseMessage(msg Message) bool {
+	held, ok := takeQuarantined(msg)
+	if !ok {
+		return false
+	}
+	held.Quarantined = ""
+	// A new ID puts it after what's already queued, so a printer paging
+	// with ?after= still gets to it.
+	held.ID = newMessageID()
+	enqueue(held)
+	return true
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Bounds on the messages waiting for the printer, what happens past them
+// is up to QueuePolicy. 0 turns either off.
+var (
+	MaxQueue      = 1000
+	MaxQueueBytes = 1 << 20 // senders' names and messages together
+)
+
+// What a full queue does with a new message.
+const (
+	DropOldest = "drop"   // make room, the sender never knows
+	RejectNew  = "reject" // refuse it with ErrMailboxFull
+)
+
+// Rejecting by default, a sender told the mailbox is full can try again
+// while a dropped message is lost without anyone knowing.
+var QueuePolicy = RejectNew
+
+// Queued messages older than this are dropped by the janitor, 0 keeps
+// them until they're printed.
+var MaxMessageAge = 7 * 24 * time.Hour
+
+// Bytes a message counts for against MaxQueueBytes.
+func (m Message) size() int {
+	return len(m.From) + len(m.Content)
+}
+
+// Whether one more message of size bytes would go past a bound.
+func queueFull(size int) bool {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	if MaxQueue > 0 && len(messages) >= MaxQueue {
+		return true
+	}
+	return MaxQueueBytes > 0 && queueBytes()+size > MaxQueueBytes
+}
+
+// Call with messagesMu held.
+func queueBytes() int {
+	n := 0
+	for _, m := range messages {
+		n += m.size()
+	}
+	return n
+}
+
+// Drops the oldest messages until the queue is within its bounds again,
+// keeping the newest whatever its size. Under RejectNew that only
+// happens when a quarantined message is released into a full queue.
+// Call with messagesMu held.
+func trimQueue() {
+	drop, bytes := 0, queueBytes()
+	for drop < len(messages)-1 && ((MaxQueue > 0 && len(messages)-drop > MaxQueue) || (MaxQueueBytes > 0 && bytes > MaxQueueBytes)) {
+		old := messages[drop]
+		log.Warn("Message queue full, dropping oldest", "max", MaxQueue, "max_bytes", MaxQueueBytes, "from", old.From, "id", old.ID)
+		bytes -= old.size()
+		drop++
+	}
+	if drop > 0 {
+		messages = append([]Message(nil), messages[drop:]...)
+	}
+}
+
+// Drops messages past MaxMessageAge every interval, in the background.
+func StartJanitor(interval time.Duration) {
+	go func() {
+		for now := range time.Tick(interval) {
+			if n := expireMessages(now); n > 0 {
+				log.Info("Dropped expired messages", "count", n, "max_age", MaxMessageAge)
+			}
+		}
+	}()
+}
+
+// Drops messages that arrived more than MaxMessageAge before now,
+// returning how many went.
+func expireMessages(now time.Time) int {
+	if MaxMessageAge <= 0 {
+		return 0
+	}
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	var kept []Message
+	for _, m := range messages {
+		if now.Sub(m.Timestamp) <= MaxMessageAge {
+			kept = append(kept, m)
+		}
+	}
+	n := len(messages) - len(kept)
+	if n > 0 {
+		messages = kept
+		queued.Store(int64(len(messages)))
+	}
+	return n
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setQueue(t *testing.T, policy string, maxMessages, maxBytes int) {
+	t.Helper()
+	resetMessages(t)
+	savedPolicy, savedMax, savedBytes, savedRate := QueuePolicy, MaxQueue, MaxQueueBytes, MessageRate
+	QueuePolicy, MaxQueue, MaxQueueBytes, MessageRate = policy, maxMessages, maxBytes, 0
+	t.Cleanup(func() {
+		QueuePolicy, MaxQueue, MaxQueueBytes, MessageRate = savedPolicy, savedMax, savedBytes, savedRate
+	})
+}
+
+func fill(t *testing.T, n int) {
+	t.Helper()
+	for i := range n {
+		if _, err := AddMessage("w", fmt.Sprintf("message %d", i), "192.0.2.1:1", ""); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+	}
+}
+
+func TestQueueRejectsByDefault(t *testing.T) {
+	if QueuePolicy != RejectNew {
+		t.Fatalf("QueuePolicy = %q, want %q", QueuePolicy, RejectNew)
+	}
+}
+
+func TestQueueFullAtMaxQueue(t *testing.T) {
+	setQueue(t, RejectNew, 1000, 0)
+	fill(t, 1000)
+	if n := len(Messages()); n != 1000 {
+		t.Fatalf("%d queued, want all 1000", n)
+	}
+	if _, err := AddMessage("w", "one too many", "192.0.2.1:1", ""); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("message 1001: %v, want ErrMailboxFull", err)
+	}
+	if n := len(Messages()); n != 1000 {
+		t.Errorf("%d queued after the rejection, want 1000", n)
+	}
+}
+
+func TestQueueDropsOldestAtMaxQueue(t *testing.T) {
+	setQueue(t, DropOldest, 1000, 0)
+	fill(t, 1000)
+	first := Messages()[0]
+	if _, err := AddMessage("w", "one more", "192.0.2.1:1", ""); err != nil {
+		t.Fatalf("message 1001: %v", err)
+	}
+	got := Messages()
+	if len(got) != 1000 || got[0].ID == first.ID || got[len(got)-1].Content != "one more" {
+		t.Errorf("queue of %d, oldest %q, newest %q; want the oldest dropped", len(got), got[0].Content, got[len(got)-1].Content)
+	}
+}
+
+// 1 MiB exactly fits, a byte more doesn't.
+func TestQueueFullAtMaxBytes(t *testing.T) {
+	setQueue(t, RejectNew, 0, 1<<20)
+	const size = 500 // "w" and 499 bytes of content
+	for i := range (1 << 20) / size {
+		content := fmt.Sprintf("%06d", i) + strings.Repeat("a", size-1-6)
+		if _, err := AddMessage("w", content, "192.0.2.1:1", ""); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+	}
+	rest := (1 << 20) % size
+	if _, err := AddMessage("w", strings.Repeat("b", rest-1), "192.0.2.1:1", ""); err != nil {
+		t.Fatalf("message filling the last %d bytes: %v", rest, err)
+	}
+	messagesMu.RLock()
+	total := queueBytes()
+	messagesMu.RUnlock()
+	if total != 1<<20 {
+		t.Fatalf("queue holds %d bytes, want exactly 1 MiB", total)
+	}
+	if _, err := AddMessage("w", "x", "192.0.2.1:1", ""); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("past 1 MiB: %v, want ErrMailboxFull", err)
+	}
+}
+
+func TestQueueDropsOldestAtMaxBytes(t *testing.T) {
+	setQueue(t, DropOldest, 0, 100)
+	for i := range 5 {
+		if _, err := AddMessage("w", fmt.Sprintf("%019d", i), "192.0.2.1:1", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := AddMessage("w", "one more", "192.0.2.1:1", ""); err != nil {
+		t.Fatal(err)
+	}
+	messagesMu.RLock()
+	total := queueBytes()
+	messagesMu.RUnlock()
+	if total > 100 {
+		t.Errorf("queue holds %d bytes, want at most 100", total)
+	}
+	if got := Messages(); got[len(got)-1].Content != "one more" {
+		t.Errorf("newest is %q, want the one just sent", got[len(got)-1].Content)
+	}
+}
+
+func TestExpireMessages(t *testing.T) {
+	resetMessages(t)
+	saved := MaxMessageAge
+	MaxMessageAge = 24 * time.Hour
+	t.Cleanup(func() { MaxMessageAge = saved })
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	messagesMu.Lock()
+	for i, age := range []time.Duration{48 * time.Hour, 24*time.Hour + time.Second, 24 * time.Hour, time.Hour} {
+		messages = append(messages, Message{ID: fmt.Sprint(i), Content: "hi", Timestamp: now.Add(-age)})
+	}
+	queued.Store(int64(len(messages)))
+	messagesMu.Unlock()
+
+	if n := expireMessages(now); n != 2 {
+		t.Errorf("expired %d, want the 2 older than a day", n)
+	}
+	got := Messages()
+	if len(got) != 2 || got[0].ID != "2" || got[1].ID != "3" {
+		t.Errorf("kept %+v, want the 2 within a day", got)
+	}
+	if queued.Load() != 2 {
+		t.Errorf("queued = %d, want 2", queued.Load())
+	}
+	if n := expireMessages(now); n != 0 {
+		t.Errorf("second run expired %d, want 0", n)
+	}
+
+	MaxMessageAge = 0
+	if n := expireMessages(now.Add(365 * 24 * time.Hour)); n != 0 {
+		t.Errorf("with no max age expired %d, want 0", n)
+	}
+}
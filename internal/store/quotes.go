@@ -0,0 +1,23 @@
+package store
+
+import "time"
+
+var quotes = []string{
+	`"Simplicity is prerequisite for reliability." - Edsger W. Dijkstra`,
+	`"Talk is cheap. Show me the code." - Linus Torvalds`,
+	`"Premature optimization is the root of all evil." - Donald Knuth`,
+	`"Clear is better than clever." - Go Proverbs`,
+	`"Make it work, make it right, make it fast." - Kent Beck`,
+	`"The best way to predict the future is to invent it." - Alan Kay`,
+	`"Programs must be written for people to read." - Harold Abelson`,
+}
+
+// Quote of the day.
+func DailyQuote(t time.Time) string {
+	return quotes[DailyIndex(t, "quote", len(quotes))]
+}
+
+// Index into projects of today's featured project, -1 if there are none.
+func FeaturedProject(t time.Time, projects []Project) int {
+	return DailyIndex(t, "featured-project", len(projects))
+}
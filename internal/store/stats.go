@@ -0,0 +1,53 @@
+package store
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Reported by /healthz and the status page, main sets it from its build
+// time version.
+var Version = "dev"
+
+var startedAt = time.Now()
+
+func Uptime() time.Duration {
+	return time.Since(startedAt)
+}
+
+var (
+	totalSessions  atomic.Int64
+	activeSessions atomic.Int64
+	totalMessages  atomic.Int64
+)
+
+// Called when a TUI session starts, pair with SessionEnded.
+func SessionStarted() {
+	totalSessions.Add(1)
+	activeSessions.Add(1)
+}
+
+func SessionEnded() {
+	activeSessions.Add(-1)
+}
+
+// TUI sessions open right now.
+func ActiveSessions() int64 {
+	return activeSessions.Load()
+}
+
+type VisitorStats struct {
+	TotalConnections int64     `json:"total_connections"`
+	ActiveSessions   int64     `json:"active_sessions"`
+	TotalMessages    int64     `json:"total_messages"`
+	AllTime          Analytics `json:"all_time"`
+}
+
+func Visitors() VisitorStats {
+	return VisitorStats{
+		TotalConnections: totalSessions.Load(),
+		ActiveSessions:   activeSessions.Load(),
+		TotalMessages:    totalMessages.Load(),
+		AllTime:          AnalyticsSnapshot(),
+	}
+}
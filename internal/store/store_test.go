@@ -0,0 +1,63 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+// Two messages alike in everything but their IDs, e.g. the same "hi"
+// sent twice in the same instant from two sessions with the same name.
+func queueTwins(t *testing.T) (Message, Message) {
+	t.Helper()
+	setQueue(t, RejectNew, 1000, 1<<20)
+	at := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	a := Message{ID: newMessageID(), From: "w", Content: "hi", Timestamp: at}
+	b := a
+	b.ID = newMessageID()
+	messagesMu.Lock()
+	messages = append(messages, a, b)
+	queued.Store(int64(len(messages)))
+	messagesMu.Unlock()
+	return a, b
+}
+
+func TestQueuePositionByID(t *testing.T) {
+	a, b := queueTwins(t)
+	if got := QueuePosition(a); got != 1 {
+		t.Errorf("first twin at %d, want 1", got)
+	}
+	if got := QueuePosition(b); got != 2 {
+		t.Errorf("second twin at %d, want 2", got)
+	}
+
+	gone := b
+	gone.ID = newMessageID()
+	if got := QueuePosition(gone); got != 0 {
+		t.Errorf("unknown ID at %d, want 0", got)
+	}
+	held := b
+	held.Quarantined = "blocklist"
+	if got := QueuePosition(held); got != 3 {
+		t.Errorf("quarantined at %d, want the place after the queue", got)
+	}
+}
+
+func TestDeleteMessageByID(t *testing.T) {
+	a, b := queueTwins(t)
+	if !DeleteMessage(b) {
+		t.Fatal("second twin not deleted")
+	}
+	msgs := Messages()
+	if len(msgs) != 1 || msgs[0].ID != a.ID {
+		t.Fatalf("left %v, want only the first twin", msgs)
+	}
+	if got := queued.Load(); got != 1 {
+		t.Errorf("queued %d, want 1", got)
+	}
+	if DeleteMessage(b) {
+		t.Error("deleted the second twin twice")
+	}
+	if got := QueuePosition(a); got != 1 {
+		t.Errorf("first twin at %d after the delete, want 1", got)
+	}
+}
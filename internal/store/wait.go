@@ -0,0 +1,12 @@
+package store
+
+// Closed and replaced by AddMessage, waking every waiting request.
+var arrived = make(chan struct{})
+
+// Closed when the next message is stored. Take it before looking at the
+// queue so a message added in between isn't missed.
+func Arrival() <-chan struct{} {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	return arrived
+}
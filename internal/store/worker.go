@@ -0,0 +1,78 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// The Cloudflare Worker holding the queue instead, if set.
+var workerURL string
+var workerSecret string
+
+// Hands every stored message to the Worker at url as well, and has
+// QueuePosition leave the queue to it. An empty url turns it off.
+func UseWorker(url, secret string) {
+	workerURL = url
+	workerSecret = secret
+}
+
+// Whether UseWorker was given a Worker.
+func HasWorker() bool {
+	return workerURL != ""
+}
+
+func postToWorker(msg Message) {
+	body, err := json.Marshal(map[string]string{
+		"from":        msg.From,
+		"content":     msg.Content,
+		"timestamp":   msg.Timestamp.Format(time.RFC3339Nano),
+		"remote_addr": msg.RemoteAddr,
+		"fingerprint": msg.Fingerprint,
+	})
+	if err != nil {
+		log.Errorf("Worker: failed to marshal message: %v", err)
+		return
+	}
+	url := workerURL + "/message?secret=" + workerSecret
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Errorf("Worker: POST /message failed: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("Worker: POST /message returned %d", resp.StatusCode)
+	}
+}
+
+// FetchFromWorker GET /next on the Worker and returns the raw plain-text
+// response body. Returns ("", false) if the queue is empty, ("", true) on error.
+func FetchFromWorker() (string, bool) {
+	url := workerURL + "/next?secret=" + workerSecret
+	resp, err := http.Get(url) //nolint:noctx
+	if err != nil {
+		log.Errorf("Worker: GET /next failed: %v", err)
+		return "", true
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return "", false
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("Worker: GET /next returned %d", resp.StatusCode)
+		return "", true
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("Worker: reading /next body failed: %v", err)
+		return "", true
+	}
+	return string(data), false
+}
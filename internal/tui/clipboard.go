@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"encoding/base64"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// Most text one copy sends, its base64 stays under the 100000 byte
+	// OSC 52 limit of hterm and friends.
+	clipboardLimit = 74994
+
+	// GNU screen drops DCS strings longer than this, so its passthrough
+	// is split into pieces.
+	screenChunk = 76
+
+	toastFor = 2 * time.Second
+)
+
+type toastTimeoutMsg struct{ seq int }
+
+var emailPattern = regexp.MustCompile(`[^\s@<>()"']+@[^\s@<>()"']+\.[A-Za-z]{2,}`)
+
+// An OSC 52 sequence putting text in the visitor's clipboard. Inside
+// tmux or screen (going by TERM) it's wrapped so they pass it on to the
+// terminal rather than keeping it.
+func osc52(term, text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	switch {
+	case strings.HasPrefix(term, "tmux"):
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(term, "screen"):
+		var b strings.Builder
+		for len(seq) > 0 {
+			n := min(screenChunk, len(seq))
+			b.WriteString("\x1bP" + seq[:n] + "\x1b\\")
+			seq = seq[n:]
+		}
+		return b.String()
+	}
+	return seq
+}
+
+// Cuts s to clipboardLimit bytes without splitting a character.
+func clipText(s string) (string, bool) {
+	if len(s) <= clipboardLimit {
+		return s, false
+	}
+	s = s[:clipboardLimit]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s, true
+}
+
+func (m Model) canYank() bool {
+	return m.State == StateContact || m.State == StateBlog || m.canSearch()
+}
+
+// What y copies: the email address on the contact page, otherwise what's
+// on screen. all (Y) takes the whole page instead.
+func (m Model) yankText(all bool) string {
+	page := ansi.Strip(m.viewportRaw)
+	if m.State == StateContact && !all {
+		if email := emailPattern.FindString(page); email != "" {
+			return email
+		}
+	}
+	if all || !m.scrolling() {
+		return strings.TrimSpace(page)
+	}
+	// Without the border round it.
+	vp := m.viewport
+	vp.Style = lipgloss.NewStyle()
+	lines := strings.Split(ansi.Strip(vp.View()), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// The session's output, shared by the program's renderer and OSC 52
+// copies. The renderer writes each frame in one Write, taking mu for
+// every write means a copy lands between frames and never inside one.
+type sessionOutput struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (o *sessionOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.w.Write(p)
+}
+
+// Sends text to the clipboard through the program's output, and says so
+// in the footer for toastFor. The write is off the update loop, a big
+// copy to a slow client shouldn't hold up keys.
+func (m *Model) yank(all bool) tea.Cmd {
+	text, cut := clipText(m.yankText(all))
+	switch {
+	case m.out == nil:
+		return m.showToast("Copying isn't available here")
+	case text == "":
+		return nil
+	}
+	toast := "Copied!"
+	if m.State == StateContact && !all && emailPattern.MatchString(text) {
+		toast = "Copied " + text + "!"
+	}
+	if cut {
+		toast = "Copied the first part, it's too long for the clipboard"
+	}
+	seq, out := osc52(m.term, text), m.out
+	return tea.Batch(m.showToast(toast), func() tea.Msg {
+		_, _ = io.WriteString(out, seq)
+		return nil
+	})
+}
+
+func (m *Model) showToast(s string) tea.Cmd {
+	m.toast = s
+	m.toastSeq++
+	seq := m.toastSeq
+	return tea.Tick(toastFor, func(time.Time) tea.Msg { return toastTimeoutMsg{seq: seq} })
+}
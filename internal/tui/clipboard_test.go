@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var osc52Payload = regexp.MustCompile(`^\x1b\]52;c;([A-Za-z0-9+/=]*)\x07$`)
+
+func decodeOSC52(t *testing.T, seq string) string {
+	t.Helper()
+	m := osc52Payload.FindStringSubmatch(seq)
+	if m == nil {
+		t.Fatalf("not an OSC 52 sequence: %q", seq)
+	}
+	data, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		t.Fatalf("bad base64 in %q: %v", seq, err)
+	}
+	return string(data)
+}
+
+func TestOSC52Encoding(t *testing.T) {
+	for _, text := range []string{
+		"will@example.com",
+		"",
+		"a",
+		"ab",
+		"line one\nline two\ttabbed",
+		"日本語 👋🏽 émoji",
+		"\x1b[31m not an escape once it's base64",
+	} {
+		seq := osc52("xterm-256color", text)
+		if got := decodeOSC52(t, seq); got != text {
+			t.Errorf("osc52(%q) decodes to %q", text, got)
+		}
+	}
+	if got, want := osc52("xterm", "hi"), "\x1b]52;c;aGk=\x07"; got != want {
+		t.Errorf("osc52(hi) = %q, want %q", got, want)
+	}
+}
+
+func TestOSC52Tmux(t *testing.T) {
+	seq := osc52("tmux-256color", "hello")
+	inner, ok := strings.CutPrefix(seq, "\x1bPtmux;")
+	if !ok || !strings.HasSuffix(inner, "\x1b\\") {
+		t.Fatalf("not a tmux passthrough: %q", seq)
+	}
+	inner = strings.ReplaceAll(strings.TrimSuffix(inner, "\x1b\\"), "\x1b\x1b", "\x1b")
+	if got := decodeOSC52(t, inner); got != "hello" {
+		t.Errorf("tmux passthrough decodes to %q", got)
+	}
+}
+
+func TestOSC52ScreenChunks(t *testing.T) {
+	text := strings.Repeat("copy me ", 40)
+	seq := osc52("screen.xterm-256color", text)
+	var joined strings.Builder
+	for _, chunk := range strings.SplitAfter(seq, "\x1b\\") {
+		if chunk == "" {
+			continue
+		}
+		body, ok := strings.CutPrefix(chunk, "\x1bP")
+		if !ok {
+			t.Fatalf("chunk without DCS: %q", chunk)
+		}
+		body = strings.TrimSuffix(body, "\x1b\\")
+		if len(body) > screenChunk {
+			t.Errorf("chunk of %d bytes, screen drops over %d", len(body), screenChunk)
+		}
+		joined.WriteString(body)
+	}
+	if got := decodeOSC52(t, joined.String()); got != text {
+		t.Errorf("screen chunks decode to %q", got)
+	}
+}
+
+func TestClipTextLimit(t *testing.T) {
+	text, cut := clipText(strings.Repeat("é", clipboardLimit))
+	if !cut || len(text) > clipboardLimit || strings.ContainsRune(text, '�') {
+		t.Fatalf("clipText: %d bytes, cut %v", len(text), cut)
+	}
+	if n := base64.StdEncoding.EncodedLen(len(text)); n >= 100000 {
+		t.Errorf("base64 of the most we copy is %d bytes, over the 100000 limit", n)
+	}
+	if text, cut := clipText("short"); cut || text != "short" {
+		t.Errorf("clipText(short) = %q, %v", text, cut)
+	}
+}
+
+func TestYankWritesOSC52(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	var buf bytes.Buffer
+	m.out = &buf
+	m = press(m, "c")
+	next, cmd := m.Update(keyMsg("y"))
+	m = next.(Model)
+	run(cmd)
+	email := decodeOSC52(t, buf.String())
+	if !strings.Contains(email, "@") || !strings.Contains(m.toast, email) {
+		t.Errorf("copied %q, toast %q", email, m.toast)
+	}
+
+	m.out = nil
+	_, cmd = m.Update(keyMsg("y"))
+	if cmd == nil {
+		t.Fatal("no toast without an output")
+	}
+}
+
+// A frame written by the renderer and a copy at the same time come out
+// one after the other, never one inside the other.
+func TestSessionOutputSerializesWrites(t *testing.T) {
+	var buf bytes.Buffer
+	out := &sessionOutput{w: &slowWriter{w: &buf}}
+	frame := strings.Repeat("F", 4096)
+	seq := osc52("xterm", strings.Repeat("c", 4096))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(2)
+		go func() { defer wg.Done(); _, _ = out.Write([]byte(frame)) }()
+		go func() { defer wg.Done(); _, _ = out.Write([]byte(seq)) }()
+	}
+	wg.Wait()
+
+	rest := buf.String()
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, frame):
+			rest = rest[len(frame):]
+		case strings.HasPrefix(rest, seq):
+			rest = rest[len(seq):]
+		default:
+			t.Fatal("writes interleaved")
+		}
+	}
+}
+
+// Writes a byte at a time, yielding between them, so unserialized
+// writers would interleave.
+type slowWriter struct{ w *bytes.Buffer }
+
+func (s *slowWriter) Write(p []byte) (int, error) {
+	for i := range p {
+		s.w.WriteByte(p[i])
+		if i%512 == 0 {
+			runtime.Gosched()
+		}
+	}
+	return len(p), nil
+}
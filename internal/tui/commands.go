@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	sshserver "github.com/will-x86/ssh-will-x86/internal/ssh"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Plain text versions of each section for non-interactive sessions.
+func Commands() sshserver.Commands {
+	return sshserver.Commands{
+		"home":     homeBody,
+		"projects": projectsText,
+		"blog":     blogContent,
+		"contact":  contactContent,
+		"resume": func() string {
+			return strings.Join([]string{homeBody(), projectsText(), contactContent()}, "\n")
+		},
+	}
+}
+
+// Files for `scp willx86.com:resume.pdf .`, the PDF is read from
+// resumePDF and left out if it's missing.
+func Downloads(resumePDF string) sshserver.Downloads {
+	text := func(render func() string) func() ([]byte, error) {
+		return func() ([]byte, error) { return []byte(render()), nil }
+	}
+	return sshserver.Downloads{
+		"resume.pdf":   func() ([]byte, error) { return os.ReadFile(resumePDF) },
+		"resume.txt":   text(Commands()["resume"]),
+		"projects.txt": text(projectsText),
+	}
+}
+
+func projectsText() string {
+	projects, err := store.Projects()
+	if err != nil {
+		log.Error("Failed to load projects", "error", err)
+		return "Projects are currently unavailable."
+	}
+
+	var b strings.Builder
+	for _, p := range projects {
+		fmt.Fprintf(&b, "%d. %s\n%s\n\n", p.ProjectNumber, p.ProjectTitle, p.Detail())
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Read-only wall of public messages on 'w', off unless -guestbook.
+// Senders opt in with ctrl+g and the owner approves each one.
+var GuestbookEnabled bool
+
+func (m Model) guestbookContent() string {
+	msgs := store.Guestbook(store.GuestbookCap)
+	if len(msgs) == 0 {
+		return "\nNothing in the guestbook yet, press 'm' to leave a message.\n"
+	}
+	var b strings.Builder
+	for _, msg := range msgs {
+		fmt.Fprintf(&b, "%s — %s\n%s\n\n", m.TxtStyle.Render(msg.From), relativeTime(m.now().Sub(msg.Timestamp)), msg.Content)
+	}
+	return lipgloss.NewStyle().Align(lipgloss.Left).Render(b.String())
+}
@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Views H goes back through, the oldest are forgotten past this.
+const historyCap = 50
+
+// One view visited, enough to open it again where it was left.
+type visit struct {
+	state    State
+	project  int // ProjectNumber of the open project, 0 for the list
+	item     int // selection in the projects list
+	selected int // ProjectNumber of that selection, to find it after a reload
+	line     int // unwrapped line at the top of the viewport, see topLine
+}
+
+func (m Model) here() visit {
+	v := visit{state: m.State, item: m.projectsList.Index(), line: m.topLine()}
+	if p, ok := m.projectsList.SelectedItem().(store.Project); ok {
+		v.selected = p.ProjectNumber
+	}
+	if m.State == StateProjects && !m.inProjectsList && m.selectedPost != nil {
+		v.project = m.selectedPost.ProjectNumber
+	}
+	return v
+}
+
+// Called when refreshProjects swaps the projects: visits to a project
+// that's gone are forgotten, the rest select where their project is now.
+func (m *Model) rebaseHistory() {
+	m.back = m.rebaseVisits(m.back)
+	m.forward = m.rebaseVisits(m.forward)
+}
+
+func (m *Model) rebaseVisits(s []visit) []visit {
+	var out []visit
+	for _, v := range s {
+		v, ok := m.rebaseVisit(v)
+		// Dropping a project can leave the same view twice in a row.
+		if !ok || len(out) > 0 && out[len(out)-1].same(v) {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func (m Model) rebaseVisit(v visit) (visit, bool) {
+	if v.state != StateProjects {
+		return v, true
+	}
+	if v.project != 0 && store.FindByNumber(m.projectsPosts, v.project) < 0 {
+		return v, false
+	}
+	if i := store.FindByNumber(m.projectsPosts, v.selected); i >= 0 {
+		v.item = i
+	} else {
+		v.item = min(v.item, max(len(m.projectsPosts)-1, 0))
+	}
+	return v, true
+}
+
+func (v visit) same(o visit) bool {
+	return v.state == o.state && v.project == o.project
+}
+
+// Remembers where prev was when next has moved somewhere else, unless
+// next got there through H or L. Called from Update.
+func recordVisit(prev Model, next *Model) {
+	if next.travelled {
+		next.travelled = false
+		return
+	}
+	if from := prev.here(); !from.same(next.here()) && from.state != StateDefault {
+		next.back = pushVisit(next.back, from)
+		next.forward = nil
+	}
+}
+
+func pushVisit(s []visit, v visit) []visit {
+	s = append(s, v)
+	if len(s) > historyCap {
+		s = append([]visit(nil), s[len(s)-historyCap:]...)
+	}
+	return s
+}
+
+// H and L, one step along the history. The view being left goes on the
+// other stack.
+func (m *Model) travel(forward bool) {
+	from, to := &m.back, &m.forward
+	if forward {
+		from, to = to, from
+	}
+	if len(*from) == 0 {
+		return
+	}
+	v := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	*to = pushVisit(*to, m.here())
+	m.rememberScroll()
+	m.numBuf = ""
+	m.travelled = true
+	m.revisit(v)
+}
+
+// Opens v again. Opening the projects may pick up a reload, which
+// rebases the rest of the history and v along with it. A page may have
+// been reloaded shorter since too, so the line is clamped.
+func (m *Model) revisit(v visit) {
+	switch v.state {
+	case StateProjects:
+		m.openSection(StateProjects)
+		m.selectedPost = nil
+		v, _ = m.rebaseVisit(v)
+		m.projectsList.Select(v.item)
+		if i := store.FindByNumber(m.projectsPosts, v.project); v.project != 0 && i >= 0 {
+			m.openProject(&m.projectsPosts[i])
+			m.scrollToLine(v.line)
+		}
+		return
+	case StateMessages:
+		m.openMessages()
+		return
+	case StateInbox:
+		if m.admin {
+			m.openInbox()
+		}
+		return
+	}
+	m.openSection(v.state)
+	m.scrollToLine(v.line)
+}
+
+// Which line of viewportRaw is at the top of the viewport. Offsets count
+// wrapped lines, this doesn't, so it still points at the same text after
+// a resize rewraps the page.
+func (m Model) topLine() int {
+	width := m.viewport.Width - m.viewport.Style.GetHorizontalFrameSize()
+	seen := 0
+	for i, l := range strings.Split(m.viewportRaw, "\n") {
+		seen += strings.Count(wrapLines(l, width), "\n") + 1
+		if seen > m.viewport.YOffset {
+			return i
+		}
+	}
+	return 0
+}
+
+// Scrolls so line n of viewportRaw is at the top, as far as it can.
+func (m *Model) scrollToLine(n int) {
+	width := m.viewport.Width - m.viewport.Style.GetHorizontalFrameSize()
+	off := 0
+	for i, l := range strings.Split(m.viewportRaw, "\n") {
+		if i == n {
+			break
+		}
+		off += strings.Count(wrapLines(l, width), "\n") + 1
+	}
+	m.viewport.SetYOffset(off)
+}
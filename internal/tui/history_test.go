@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// A model with its first project long enough to scroll, each paragraph
+// long enough to wrap differently at different widths. Sessions share
+// the cached projects, so it's stretched in place for the test.
+func longProjectModel(t *testing.T, width int) Model {
+	t.Helper()
+	posts, err := store.Projects()
+	if err != nil || len(posts) == 0 {
+		t.Fatalf("no projects to stretch: %v", err)
+	}
+	saved := posts[0].ProjectContent
+	t.Cleanup(func() { posts[0].ProjectContent = saved })
+	var paras []string
+	for i := range 40 {
+		paras = append(paras, fmt.Sprintf("Paragraph %02d %s", i, strings.Repeat("words that wrap ", 10)))
+	}
+	posts[0].ProjectContent = strings.Join(paras, "\n\n")
+
+	m := press(newTestModel(t, width, 30), "p")
+	for i, item := range m.projectsList.Items() {
+		if p, ok := item.(store.Project); ok && p.ProjectNumber == posts[0].ProjectNumber {
+			m.projectsList.Select(i)
+		}
+	}
+	return press(m, "enter")
+}
+
+// The text of the line at the top of the viewport.
+func topText(m Model) string {
+	return strings.TrimSpace(ansi.Strip(strings.Split(m.viewportRaw, "\n")[m.topLine()]))
+}
+
+// The first line inside the viewport's border.
+func shownText(m Model) string {
+	lines := strings.Split(ansi.Strip(m.viewport.View()), "\n")
+	return strings.Trim(lines[1], "|│ ")
+}
+
+// H back to a project after a resize rewrapped it lands on the same
+// text, not the same wrapped line number.
+func TestHistoryScrollAfterResize(t *testing.T) {
+	for _, tt := range []struct{ from, to int }{{120, 70}, {70, 120}} {
+		name := fmt.Sprintf("%d to %d columns", tt.from, tt.to)
+		m := press(longProjectModel(t, tt.from), "d", "d", "d")
+		want := topText(m)
+		if !strings.HasPrefix(want, "Paragraph") {
+			t.Fatalf("%s: scrolled to %q, want a paragraph at the top", name, want)
+		}
+		offset := m.viewport.YOffset
+
+		m = press(m, "c")
+		m = send(m, tea.WindowSizeMsg{Width: tt.to, Height: 30})
+		m = press(m, "H")
+		if m.State != StateProjects || m.selectedPost == nil || !strings.HasPrefix(m.selectedPost.ProjectContent, "Paragraph 00") {
+			t.Fatalf("%s: H didn't go back to the project", name)
+		}
+		if got := topText(m); got != want {
+			t.Errorf("%s: back at %q, want %q", name, got, want)
+		}
+		if got := shownText(m); !strings.HasPrefix(want, got) || got == "" {
+			t.Errorf("%s: showing %q, want the start of %q", name, got, want)
+		}
+		if m.viewport.YOffset == offset {
+			t.Errorf("%s: offset %d unchanged, rewrapping should have moved it", name, offset)
+		}
+
+		// And forward and back again at the new width.
+		m = press(m, "L", "H")
+		if got := topText(m); got != want {
+			t.Errorf("%s: after L and H at %q, want %q", name, got, want)
+		}
+	}
+}
+
+// Reopening a project from the list after a resize is back at the same
+// text too.
+func TestReopenScrollAfterResize(t *testing.T) {
+	m := press(longProjectModel(t, 120), "d", "d", "d")
+	want := topText(m)
+	m = press(m, "backspace")
+	m = send(m, tea.WindowSizeMsg{Width: 70, Height: 30})
+	m = press(m, "enter")
+	if got := topText(m); got != want {
+		t.Errorf("reopened at %q, want %q", got, want)
+	}
+}
+
+// A session that's been browsing an older snapshot of the projects, with
+// a project 999 a reload has since removed. Visits 999, then second, then
+// leaves for contact.
+func staleProjectsModel(t *testing.T) (Model, store.Project) {
+	t.Helper()
+	m := press(newTestModel(t, 100, 30), "p")
+	current, err := store.Projects()
+	if err != nil || len(current) < 2 {
+		t.Fatalf("need two projects: %v", err)
+	}
+	stale := append([]store.Project{{ProjectTitle: "Gone", ProjectNumber: 999, ProjectContent: "removed since"}}, current...)
+	m.projectsPosts = stale
+	m.projectsList.SetItems(projectItems(stale))
+
+	m.projectsList.Select(0)
+	m = press(m, "enter")
+	if m.selectedPost == nil || m.selectedPost.ProjectNumber != 999 {
+		t.Fatal("didn't open the stale project")
+	}
+	m = press(m, "backspace")
+	m.projectsList.Select(2)
+	m = press(m, "enter", "c")
+	return m, current[1]
+}
+
+// Walks H back to the start, failing on any visit to the removed project.
+func backToStart(t *testing.T, m Model) Model {
+	t.Helper()
+	for range historyCap {
+		if len(m.back) == 0 {
+			return m
+		}
+		m = press(m, "H")
+		if m.selectedPost != nil && m.selectedPost.ProjectNumber == 999 {
+			t.Fatal("H went back to a project the reload removed")
+		}
+		if p, ok := m.projectsList.SelectedItem().(store.Project); m.State == StateProjects && ok && p.ProjectNumber == 999 {
+			t.Fatal("H selected a project the reload removed")
+		}
+	}
+	t.Fatal("history never ran out")
+	return m
+}
+
+func TestHistoryAfterReload(t *testing.T) {
+	m, second := staleProjectsModel(t)
+
+	// Opening the projects picks up the reload.
+	m = press(m, "p")
+	for _, v := range append(m.back, m.forward...) {
+		if v.project == 999 {
+			t.Fatalf("history still has the removed project: %+v", m.back)
+		}
+	}
+	m = press(m, "H", "H")
+	if m.selectedPost == nil || m.selectedPost.ProjectNumber != second.ProjectNumber {
+		t.Fatalf("H went to %v, want project %d", m.selectedPost, second.ProjectNumber)
+	}
+	if got := m.projectsList.Index(); got != 1 {
+		t.Errorf("selection at %d, want project %d where it is now, at 1", got, second.ProjectNumber)
+	}
+	backToStart(t, m)
+}
+
+// H straight to a project visit picks up the reload on the way, the
+// visit it's going to included.
+func TestHistoryReloadOnTheWayBack(t *testing.T) {
+	m, second := staleProjectsModel(t)
+	m = press(m, "H")
+	if m.selectedPost == nil || m.selectedPost.ProjectNumber != second.ProjectNumber {
+		t.Fatalf("H went to %v, want project %d", m.selectedPost, second.ProjectNumber)
+	}
+	if got := m.projectsList.Index(); got != 1 {
+		t.Errorf("selection at %d, want 1", got)
+	}
+	backToStart(t, m)
+}
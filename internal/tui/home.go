@@ -0,0 +1,48 @@
+package tui
+
+// Big "willx86.com" above the bio when the window is wide enough.
+var ShowBanner = true
+
+// Switches to one of the plain sections, each starting at the top.
+func (m *Model) openSection(s State) {
+	switch s {
+	case StateProjects:
+		m.State = StateProjects
+		m.inProjectsList = true
+		m.refreshProjects()
+		return
+	case StateBlog:
+		m.setViewportContent(hyperlinks(blogContent(), m.linksEnabled()))
+	case StateContact:
+		m.setViewportContent(hyperlinks(contactContent(), m.linksEnabled()))
+	case StateStats:
+		m.setViewportContent(m.statsContent())
+	case StateStatus:
+		m.setViewportContent(m.statusContent())
+	case StateGuestbook:
+		if !GuestbookEnabled {
+			return
+		}
+		m.setViewportContent(m.guestbookContent())
+	default:
+		m.goHome()
+		return
+	}
+	m.State = s
+	m.viewport.GotoTop()
+}
+
+func (m *Model) goHome() {
+	m.State = StateHome
+	m.setViewportContent(m.homeContent())
+	m.viewport.GotoTop()
+}
+
+// The banner depends on the width and theme, so the home screen is
+// redrawn (keeping its scroll) when either changes.
+func (m *Model) refreshHome() {
+	if m.State == StateHome {
+		m.viewportRaw = m.homeContent()
+		m.refreshViewport()
+	}
+}
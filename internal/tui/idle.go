@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Zero disables either limit.
+var (
+	IdleTimeout        = 10 * time.Minute
+	MaxSessionDuration = 2 * time.Hour
+)
+
+const (
+	idleCheckEvery = 15 * time.Second
+	// How long the goodbye message shows before the session closes.
+	idleGoodbye = 3 * time.Second
+)
+
+type idleCheckMsg struct{}
+
+type idleQuitMsg struct{}
+
+func idleCheck() tea.Cmd {
+	return tea.Tick(idleCheckEvery, func(time.Time) tea.Msg { return idleCheckMsg{} })
+}
+
+// Called on every check tick, starts the goodbye once a limit is hit.
+func (m Model) checkIdle() (Model, tea.Cmd) {
+	if m.disconnecting != "" {
+		return m, nil
+	}
+	now := m.now()
+	switch {
+	case IdleTimeout > 0 && now.Sub(m.lastActivity) >= IdleTimeout:
+		m.disconnecting = "Disconnected due to inactivity, thanks for visiting!"
+	case MaxSessionDuration > 0 && now.Sub(m.startedAt) >= MaxSessionDuration:
+		m.disconnecting = "Session time limit reached, thanks for visiting!"
+	default:
+		return m, idleCheck()
+	}
+	return m, tea.Tick(idleGoodbye, func(time.Time) tea.Msg { return idleQuitMsg{} })
+}
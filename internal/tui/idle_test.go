@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A model whose clock only moves when the test says so, with the
+// session started and last active at the clock's start.
+func idleModel(t *testing.T) (Model, *time.Time) {
+	t.Helper()
+	m := newTestModel(t, 100, 30)
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.startedAt, m.lastActivity = now, now
+	return m, &now
+}
+
+func TestIdleTimeout(t *testing.T) {
+	m, now := idleModel(t)
+	*now = now.Add(IdleTimeout - time.Second)
+	m, cmd := m.checkIdle()
+	if m.disconnecting != "" || cmd == nil {
+		t.Fatalf("before IdleTimeout: disconnecting %q, next check %v", m.disconnecting, cmd != nil)
+	}
+
+	// A key resets the timer.
+	m = press(m, "j")
+	*now = now.Add(IdleTimeout - time.Second)
+	m = send(m, idleCheckMsg{})
+	if m.disconnecting != "" {
+		t.Fatal("disconnected though a key was pressed within IdleTimeout")
+	}
+
+	*now = now.Add(time.Second)
+	m, cmd = m.checkIdle()
+	if m.disconnecting == "" || cmd == nil {
+		t.Fatal("not disconnecting at IdleTimeout")
+	}
+	if !strings.Contains(m.View(), "inactivity") {
+		t.Errorf("goodbye not shown: %q", m.View())
+	}
+
+	// Keys during the goodbye change nothing, the quit still comes.
+	m = press(m, "p")
+	if m.State != StateHome {
+		t.Errorf("key during the goodbye moved to %v", m.State)
+	}
+	_, cmd = m.Update(idleQuitMsg{})
+	if msgs := run(cmd); len(msgs) != 1 || msgs[0] != tea.Quit() {
+		t.Errorf("idleQuitMsg gave %v, want quit", msgs)
+	}
+}
+
+func TestMaxSessionDuration(t *testing.T) {
+	m, now := idleModel(t)
+	// Busy the whole time, so only the session limit can end it.
+	for elapsed := time.Duration(0); elapsed < MaxSessionDuration; elapsed += IdleTimeout / 2 {
+		*now = now.Add(IdleTimeout / 2)
+		m = press(m, "j")
+		m = send(m, idleCheckMsg{})
+	}
+	if !strings.Contains(m.disconnecting, "time limit") {
+		t.Errorf("after MaxSessionDuration: disconnecting %q", m.disconnecting)
+	}
+}
+
+func TestIdleLimitsDisabled(t *testing.T) {
+	savedIdle, savedMax := IdleTimeout, MaxSessionDuration
+	IdleTimeout, MaxSessionDuration = 0, 0
+	t.Cleanup(func() { IdleTimeout, MaxSessionDuration = savedIdle, savedMax })
+
+	m, now := idleModel(t)
+	*now = now.Add(24 * time.Hour)
+	m, cmd := m.checkIdle()
+	if m.disconnecting != "" || cmd == nil {
+		t.Error("disconnecting with both limits off")
+	}
+}
@@ -0,0 +1,235 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// The owner's login, both the username and one of the keys have to match
+// to get the inbox. Empty AdminUser turns it off.
+var (
+	AdminUser string
+	AdminKeys []ssh.PublicKey
+)
+
+func isAdmin(user string, key ssh.PublicKey) bool {
+	if AdminUser == "" || user != AdminUser || key == nil {
+		return false
+	}
+	for _, k := range AdminKeys {
+		if ssh.KeysEqual(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+type inboxItem struct {
+	store.Message
+	printed bool // only left in the guestbook, waiting for approval
+}
+
+func (i inboxItem) Title() string {
+	title := fmt.Sprintf("%s — %s", i.From, i.Timestamp.Format("2006-01-02 15:04"))
+	if i.printed {
+		title += " (printed)"
+	}
+	return title
+}
+
+func (i inboxItem) Description() string {
+	first, _, _ := strings.Cut(i.Content, "\n")
+	switch {
+	case i.Quarantined != "":
+		return i.Quarantined + ": " + first
+	case i.Approved:
+		return "✓ " + first
+	case i.Public:
+		return "◌ " + first
+	}
+	return first
+}
+
+func (i inboxItem) FilterValue() string { return i.From + " " + i.Content }
+
+func newInboxList(width, height int) list.Model {
+	l := list.New(nil, list.NewDefaultDelegate(), width, height)
+	l.Title = "Inbox"
+	l.SetShowHelp(false)
+	l.KeyMap.Quit.SetEnabled(false)
+	l.SetStatusBarItemName("message", "messages")
+	return l
+}
+
+// Reloads the list from the store, the printer may have taken some. Public
+// ones it took are still listed from the guestbook until they're approved,
+// or there'd be nowhere to approve them.
+func (m *Model) refreshInbox() {
+	var items []list.Item
+	if m.inQuarantine {
+		m.inbox.Title = "Quarantine"
+		for _, msg := range store.Quarantine() {
+			items = append(items, inboxItem{Message: msg})
+		}
+		m.inbox.SetItems(items)
+		return
+	}
+
+	m.inbox.Title = "Inbox"
+	queued := map[string]bool{}
+	for _, msg := range store.Messages() {
+		queued[msg.ID] = true
+		items = append(items, inboxItem{Message: msg})
+	}
+	for _, msg := range store.PendingGuestbook() {
+		if !queued[msg.ID] {
+			items = append(items, inboxItem{Message: msg, printed: true})
+		}
+	}
+	m.inbox.SetItems(items)
+}
+
+func (m *Model) openInbox() {
+	m.State = StateInbox
+	m.inInboxList = true
+	m.inQuarantine = false
+	m.openMessage = nil
+	m.refreshInbox()
+}
+
+func (m *Model) readMessage(msg store.Message) {
+	m.openMessage = &msg
+	m.inInboxList = false
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\nTime: %s\n", msg.From, msg.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	if msg.RemoteAddr != "" {
+		fmt.Fprintf(&b, "Remote: %s\n", msg.RemoteAddr)
+	}
+	if msg.Fingerprint != "" {
+		fmt.Fprintf(&b, "Key: %s\n", msg.Fingerprint)
+	}
+	switch {
+	case msg.Quarantined != "":
+		fmt.Fprintf(&b, "Held back: %s (y to print it anyway)\n", msg.Quarantined)
+	case msg.Approved:
+		b.WriteString("Guestbook: approved\n")
+	case msg.Public:
+		b.WriteString("Guestbook: waiting for approval (a)\n")
+	}
+	b.WriteString("\n" + msg.Content)
+	m.setViewportContent(b.String())
+	m.viewport.GotoTop()
+}
+
+func (m *Model) deleteMessage(msg store.Message) {
+	deleted := store.DeleteMessage
+	if msg.Quarantined != "" {
+		deleted = store.DeleteQuarantined
+	}
+	ok := deleted(msg)
+	// An entry waiting for approval goes too, or it'd be listed again as
+	// printed. For one that's printed it's all there is left.
+	if msg.Public && !msg.Approved && store.DeleteGuestbookEntry(msg) {
+		ok = true
+	}
+	if ok {
+		log.Info("Message deleted from inbox", "from", msg.From, "admin", AdminUser)
+	}
+	m.openMessage = nil
+	m.inInboxList = true
+	m.refreshInbox()
+}
+
+// Sends a quarantined msg on to the printer.
+func (m *Model) releaseMessage(msg store.Message) {
+	if msg.Quarantined != "" && store.ReleaseMessage(msg) {
+		log.Info("Quarantined message released", "from", msg.From, "reason", msg.Quarantined, "admin", AdminUser)
+	}
+	m.openMessage = nil
+	m.inInboxList = true
+	m.refreshInbox()
+}
+
+// Flips whether msg shows in the guestbook, private messages can't be.
+func (m *Model) approveMessage(msg store.Message) {
+	if store.ApproveMessage(msg, !msg.Approved) {
+		log.Info("Message guestbook approval changed", "from", msg.From, "approved", !msg.Approved, "admin", AdminUser)
+		msg.Approved = !msg.Approved
+	}
+	if m.openMessage != nil {
+		m.readMessage(msg)
+	}
+	m.refreshInbox()
+}
+
+// Inbox keys, reports false for keys it leaves to the global switch.
+func (m Model) updateInbox(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	if m.inInboxList && m.inbox.SettingFilter() {
+		var cmd tea.Cmd
+		m.inbox, cmd = m.inbox.Update(msg)
+		return m, cmd, true
+	}
+
+	if !m.inInboxList {
+		switch msg.String() {
+		case "backspace", "esc":
+			m.inInboxList = true
+			m.openMessage = nil
+			return m, nil, true
+		case "x":
+			if m.openMessage != nil {
+				m.deleteMessage(*m.openMessage)
+			}
+			return m, nil, true
+		case "a":
+			if m.openMessage != nil {
+				m.approveMessage(*m.openMessage)
+			}
+			return m, nil, true
+		case "y":
+			if m.openMessage != nil {
+				m.releaseMessage(*m.openMessage)
+			}
+			return m, nil, true
+		}
+		return m, nil, false
+	}
+
+	switch msg.String() {
+	case "enter":
+		if i, ok := m.inbox.SelectedItem().(inboxItem); ok {
+			m.readMessage(i.Message)
+		}
+		return m, nil, true
+	case "x":
+		if i, ok := m.inbox.SelectedItem().(inboxItem); ok {
+			m.deleteMessage(i.Message)
+		}
+		return m, nil, true
+	case "a":
+		if i, ok := m.inbox.SelectedItem().(inboxItem); ok {
+			m.approveMessage(i.Message)
+		}
+		return m, nil, true
+	case "y":
+		if i, ok := m.inbox.SelectedItem().(inboxItem); ok {
+			m.releaseMessage(i.Message)
+		}
+		return m, nil, true
+	case "f":
+		m.inQuarantine = !m.inQuarantine
+		m.inbox.ResetFilter()
+		m.refreshInbox()
+		return m, nil, true
+	case "r":
+		m.refreshInbox()
+		return m, nil, true
+	}
+	return m, nil, false
+}
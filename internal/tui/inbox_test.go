@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+func newAdminModel(t *testing.T) Model {
+	t.Helper()
+	return NewModel(Visitor{
+		Term:        "xterm-256color",
+		RemoteAddr:  "192.0.2.1:1234",
+		Username:    "owner",
+		Fingerprint: "SHA256:owner",
+		Admin:       true,
+		Width:       100,
+		Height:      30,
+	}, lipgloss.NewRenderer(io.Discard))
+}
+
+// A public message the printer has already taken, only its guestbook
+// entry is left.
+func printedPublic(t *testing.T, content string) store.Message {
+	t.Helper()
+	msg, err := store.AddMessage("visitor", content, "192.0.2.9:1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, err = store.Publish(msg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DeleteGuestbookEntry(msg) })
+	if !store.DeleteMessage(msg) {
+		t.Fatal("message not queued")
+	}
+	return msg
+}
+
+func selectInboxItem(t *testing.T, m Model, id string) Model {
+	t.Helper()
+	for i, item := range m.inbox.Items() {
+		if it, ok := item.(inboxItem); ok && it.ID == id {
+			m.inbox.Select(i)
+			return m
+		}
+	}
+	t.Fatalf("message %s not in the inbox", id)
+	return m
+}
+
+func TestInboxApprovesPrintedMessage(t *testing.T) {
+	msg := printedPublic(t, "printed and public "+t.Name())
+	m := press(newAdminModel(t), "i")
+	if m.State != StateInbox {
+		t.Fatalf("after i: state %v, want the inbox", m.State)
+	}
+	m = selectInboxItem(t, m, msg.ID)
+	if it := m.inbox.SelectedItem().(inboxItem); !it.printed || !strings.Contains(it.Title(), "(printed)") {
+		t.Errorf("printed message listed as %q", it.Title())
+	}
+
+	m = press(m, "a")
+	approved := false
+	for _, e := range store.Guestbook(store.GuestbookCap) {
+		approved = approved || e.ID == msg.ID
+	}
+	if !approved {
+		t.Fatal("a didn't approve the printed message")
+	}
+	for _, item := range m.inbox.Items() {
+		if item.(inboxItem).ID == msg.ID {
+			t.Error("approved printed message still in the inbox")
+		}
+	}
+}
+
+func TestInboxDeletesPrintedMessage(t *testing.T) {
+	msg := printedPublic(t, "printed then rejected "+t.Name())
+	m := selectInboxItem(t, press(newAdminModel(t), "i"), msg.ID)
+	m = press(m, "x")
+	for _, e := range store.PendingGuestbook() {
+		if e.ID == msg.ID {
+			t.Fatal("x left the guestbook entry waiting")
+		}
+	}
+	for _, item := range m.inbox.Items() {
+		if item.(inboxItem).ID == msg.ID {
+			t.Error("deleted message still in the inbox")
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package tui
+
+import "github.com/charmbracelet/bubbles/key"
+
+// Bindings shown in the help overlay and, through footerBindings, the
+// footer.
+type keyMap struct {
+	Quit      key.Binding
+	Help      key.Binding
+	Home      key.Binding
+	Projects  key.Binding
+	Blog      key.Binding
+	Contact   key.Binding
+	Message   key.Binding
+	Theme     key.Binding
+	Inbox     key.Binding
+	Stats     key.Binding
+	Status    key.Binding
+	Guestbook key.Binding
+	Tabs      key.Binding
+	History   key.Binding
+	Command   key.Binding
+
+	Down     key.Binding
+	Up       key.Binding
+	PageDown key.Binding
+	PageUp   key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+
+	Featured key.Binding
+	Select   key.Binding
+	Number   key.Binding
+	Filter   key.Binding
+	Page     key.Binding
+	Back     key.Binding
+	Link     key.Binding
+	Search   key.Binding
+	Next     key.Binding
+	Prev     key.Binding
+	Scroll   key.Binding // the footer's one line version of Down, Up and friends
+	Yank     key.Binding
+	YankAll  key.Binding
+
+	CopyEmail key.Binding
+
+	// Footer hints while one thing is being done.
+	ApplyFilter key.Binding
+	ClearFilter key.Binding
+	RunSearch   key.Binding
+	ClearSearch key.Binding
+	NextLink    key.Binding
+	HideLink    key.Binding
+	RunCommand  key.Binding
+
+	Send       key.Binding
+	ChangeName key.Binding
+	Public     key.Binding
+	Restore    key.Binding
+	Clear      key.Binding
+	Receipt    key.Binding
+	Cancel     key.Binding
+	Confirm    key.Binding
+	SendNow    key.Binding
+	Edit       key.Binding
+	Leave      key.Binding
+	Undo       key.Binding
+
+	VimNormal key.Binding
+	VimInsert key.Binding
+	VimMove   key.Binding
+	VimDelete key.Binding
+
+	Read     key.Binding
+	Delete   key.Binding
+	Approve  key.Binding
+	Release  key.Binding
+	Filtered key.Binding
+	Refresh  key.Binding
+	ToInbox  key.Binding
+}
+
+var keys = keyMap{
+	Quit:      key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	Help:      key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+	Home:      key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "home")),
+	Projects:  key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "projects")),
+	Blog:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "blog")),
+	Contact:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "contact")),
+	Message:   key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "message me")),
+	Theme:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "next theme")),
+	Inbox:     key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "inbox")),
+	Stats:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "visitor stats")),
+	Status:    key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "server status")),
+	Guestbook: key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "guestbook")),
+	Command:   key.NewBinding(key.WithKeys(":"), key.WithHelp(":", "command, e.g. :projects 2")),
+	Tabs:      key.NewBinding(key.WithKeys("tab", "shift+tab", "left", "right"), key.WithHelp("tab/←/→", "next/previous tab")),
+	History:   key.NewBinding(key.WithKeys("H", "L"), key.WithHelp("H/L", "back/forward")),
+
+	Down:     key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/↓", "down")),
+	Up:       key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/↑", "up")),
+	PageDown: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "down 10 lines")),
+	PageUp:   key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "up 10 lines")),
+	Top:      key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "top")),
+	Bottom:   key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "bottom")),
+
+	Featured: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open featured project")),
+	Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open project")),
+	Number:   key.NewBinding(key.WithKeys("0", "1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("0-9", "go to project")),
+	Filter:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+	Page:     key.NewBinding(key.WithKeys("h", "l", "left", "right"), key.WithHelp("h/l", "page")),
+	Back:     key.NewBinding(key.WithKeys("backspace"), key.WithHelp("backspace", "back")),
+	Link:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "next link")),
+	Search:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+	Next:     key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next match")),
+	Prev:     key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "previous match")),
+	Scroll:   key.NewBinding(key.WithKeys("j", "k", "d", "u"), key.WithHelp("j/k/d/u", "scroll")),
+	Yank:     key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy")),
+	YankAll:  key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy whole page")),
+
+	CopyEmail: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy email")),
+
+	ApplyFilter: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply filter")),
+	ClearFilter: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear filter")),
+	RunSearch:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "search")),
+	ClearSearch: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear")),
+	NextLink:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "next")),
+	HideLink:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "hide")),
+	RunCommand:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "run")),
+
+	Send:       key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "preview and send")),
+	ChangeName: key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "change name")),
+	Public:     key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "also show in guestbook")),
+	Clear:      key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "clear")),
+	Restore:    key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "restore draft")),
+	Receipt:    key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "receipt preview")),
+	Cancel:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+	Confirm:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm name")),
+	SendNow:    key.NewBinding(key.WithKeys("y", "enter"), key.WithHelp("y/enter", "send it")),
+	Edit:       key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "keep editing")),
+	Leave:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back (keeps draft)")),
+	Undo:       key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo")),
+
+	VimNormal: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "normal mode")),
+	VimInsert: key.NewBinding(key.WithKeys("i", "a", "A", "o"), key.WithHelp("i/a/A/o", "insert")),
+	VimMove:   key.NewBinding(key.WithKeys("h", "j", "k", "l", "w", "b", "0", "$"), key.WithHelp("hjkl/w/b/0/$", "move")),
+	VimDelete: key.NewBinding(key.WithKeys("x", "d"), key.WithHelp("x/dd", "delete char/line")),
+
+	Read:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "read")),
+	Delete:   key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "delete")),
+	Approve:  key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "guestbook approve")),
+	Release:  key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "print anyway")),
+	Filtered: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "inbox/quarantine")),
+	Refresh:  key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+	ToInbox:  key.NewBinding(key.WithKeys("backspace", "esc"), key.WithHelp("backspace", "inbox")),
+}
+
+// Help overlay columns for the current state, globals first.
+func (m Model) helpGroups() [][]key.Binding {
+	global := []key.Binding{keys.Home, keys.Projects, keys.Blog, keys.Contact, keys.Message, keys.Tabs, keys.History, keys.Command, keys.Stats, keys.Status, keys.Theme, keys.Help, keys.Quit}
+	if GuestbookEnabled {
+		global = append(global, keys.Guestbook)
+	}
+	if m.admin {
+		global = append(global, keys.Inbox)
+	}
+	scroll := []key.Binding{keys.Down, keys.Up, keys.PageDown, keys.PageUp, keys.Top, keys.Bottom}
+	search := []key.Binding{keys.Search, keys.Next, keys.Prev}
+
+	switch m.State {
+	case StateProjects:
+		if m.inProjectsList {
+			return [][]key.Binding{global, {keys.Down, keys.Up, keys.Page, keys.Select, keys.Number, keys.Filter}}
+		}
+		return [][]key.Binding{global, append(scroll, keys.Back, keys.Link), append(search, keys.Yank, keys.YankAll)}
+	case StateMessages:
+		if m.editingName {
+			return [][]key.Binding{{keys.Confirm, keys.Cancel}}
+		}
+		if m.confirming {
+			return [][]key.Binding{{keys.SendNow, keys.Edit, keys.Cancel}}
+		}
+		editor := []key.Binding{keys.Send, keys.ChangeName, keys.Receipt, keys.Clear, keys.Restore, keys.Leave, keys.Help}
+		if GuestbookEnabled {
+			editor = append(editor, keys.Public)
+		}
+		if VimInput {
+			return [][]key.Binding{editor, {keys.VimNormal, keys.VimInsert, keys.VimMove, keys.VimDelete}}
+		}
+		return [][]key.Binding{editor}
+	case StateHome:
+		return [][]key.Binding{global, {keys.Featured}, search}
+	case StateContact:
+		return [][]key.Binding{global, append(scroll, keys.CopyEmail, keys.YankAll), search}
+	case StateBlog:
+		return [][]key.Binding{global, append(scroll, keys.Yank, keys.YankAll), search}
+	case StateInbox:
+		if m.inInboxList {
+			return [][]key.Binding{global, {keys.Down, keys.Up, keys.Read, keys.Delete, keys.Approve, keys.Release, keys.Filtered, keys.Refresh, keys.Filter}}
+		}
+		return [][]key.Binding{global, append(scroll, keys.Delete, keys.Approve, keys.Release), search}
+	case StateStats, StateStatus, StateGuestbook:
+		return [][]key.Binding{global, scroll, search}
+	default:
+		return [][]key.Binding{global}
+	}
+}
+
+// Footer hints for the current state, less ? which footerControls adds.
+// The globals are dropped first when they don't fit, nil globals mean
+// the state's keys are all there is, e.g. while typing a message.
+func (m Model) footerBindings() (local, global []key.Binding) {
+	global = []key.Binding{keys.Quit, keys.Home, keys.Projects, keys.Blog, keys.Contact, keys.Message}
+
+	switch m.State {
+	case StateProjects:
+		if !m.inProjectsList {
+			return []key.Binding{keys.Back, keys.Scroll, keys.Search, keys.Link}, global
+		}
+		switch {
+		case m.projectsList.SettingFilter():
+			return []key.Binding{keys.ApplyFilter, keys.Cancel}, nil
+		case m.projectsList.IsFiltered():
+			local = append(local, keys.ClearFilter)
+		default:
+			local = append(local, keys.Number, keys.Filter)
+		}
+		if m.projectsList.Paginator.TotalPages > 1 {
+			local = append(local, keys.Page)
+		}
+		return local, global
+	case StateMessages:
+		switch {
+		case m.readOnly || m.messageSent:
+			return nil, global
+		case m.holding():
+			return []key.Binding{keys.Undo}, nil
+		case m.sending:
+			return nil, nil
+		case m.editingName:
+			return []key.Binding{keys.Confirm, keys.Cancel}, nil
+		case m.confirming:
+			return []key.Binding{keys.SendNow, keys.Edit, keys.Cancel}, nil
+		}
+		return []key.Binding{keys.Send, keys.Receipt, keys.Clear, keys.Leave}, nil
+	case StateInbox:
+		mark := keys.Approve
+		if m.inQuarantine {
+			mark = keys.Release
+		}
+		global = []key.Binding{keys.Quit, keys.Home}
+		if m.inInboxList {
+			return []key.Binding{keys.Read, keys.Delete, mark, keys.Filtered, keys.Refresh}, global
+		}
+		return []key.Binding{keys.ToInbox, keys.Delete, mark, keys.Scroll}, global
+	case StateContact:
+		return []key.Binding{keys.CopyEmail}, global
+	case StateBlog:
+		return []key.Binding{keys.Yank}, global
+	}
+	return nil, global
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// A binding left out of the keys literal is the zero value, which the
+// footer and help quietly render as nothing.
+func TestEveryBindingSet(t *testing.T) {
+	v := reflect.ValueOf(keys)
+	for i := range v.NumField() {
+		b := v.Field(i).Interface().(key.Binding)
+		if len(b.Keys()) == 0 || b.Help().Key == "" || b.Help().Desc == "" {
+			t.Errorf("keys.%s has no keys or help", v.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestOpenInboxMessageFooter(t *testing.T) {
+	msg := printedPublic(t, "footer check "+t.Name())
+	m := press(selectInboxItem(t, press(newAdminModel(t), "i"), msg.ID), "enter")
+	if m.inInboxList {
+		t.Fatal("enter didn't open the message")
+	}
+	if footer := ansi.Strip(m.footerControls()); !strings.Contains(footer, "backspace inbox") {
+		t.Errorf("footer %q has no way back to the inbox", footer)
+	}
+	if m = press(m, "backspace"); !m.inInboxList {
+		t.Error("backspace didn't go back to the inbox")
+	}
+}
@@ -0,0 +1,561 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// How long the second q has to arrive to quit.
+const quitConfirmWindow = 2 * time.Second
+
+type quitTimeoutMsg struct{ seq int }
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n, ok := next.(Model); ok {
+		if n.State != m.State {
+			store.RecordView(n.State.String())
+		}
+		if p := n.prefs(); n.fingerprint != "" && p != m.prefs() {
+			store.SetPrefs(n.fingerprint, p)
+		}
+		recordVisit(m, &n)
+		tick := statusCmd(m, &n)
+		return n, tea.Batch(cmd, mouseCmd(m, n), tick)
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg.(type) {
+	case tea.KeyMsg, tea.WindowSizeMsg, tea.MouseMsg:
+		m.lastActivity = m.now()
+	case idleCheckMsg:
+		return m.checkIdle()
+	case idleQuitMsg:
+		return m, tea.Quit
+	}
+	if m.disconnecting != "" {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.viewport.Width = max(msg.Width, 0)
+		m.viewport.Height = max(msg.Height-HeaderHeight-FooterHeight, 0)
+		m.projectsList.SetWidth(max(msg.Width, 0))
+		m.projectsList.SetHeight(max(msg.Height-HeaderHeight-FooterHeight-2, 0))
+		m.inbox.SetSize(max(msg.Width, 0), max(msg.Height-HeaderHeight-FooterHeight-2, 0))
+		m.sizeEditor(msg.Width)
+		m.nameInput.Width = nameInputWidth(msg.Width)
+		// Whichever input is being typed in keeps the cursor.
+		if m.editingName {
+			m.nameInput.Focus()
+			m.messageInput.Blur()
+		} else if m.State == StateMessages && !m.confirming && !m.holding() && !m.sending && !m.messageSent {
+			m.messageInput.Focus()
+		}
+		m.searchInput.Width = max(msg.Width-2, 0)
+		m.commandInput.Width = max(msg.Width-2, 0)
+		m.refreshHome()
+		m.refreshViewport()
+
+	case tea.KeyMsg:
+		// Nothing to interact with until the window is big enough.
+		if m.tooSmall() {
+			if msg.String() == "ctrl+c" || msg.String() == "q" {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		// Only '?' and esc are taken while help is open, the rest falls through.
+		if m.helpToggle(msg) {
+			m.showHelp = !m.showHelp
+			return m, nil
+		}
+		if m.showHelp && msg.String() == "esc" {
+			m.showHelp = false
+			return m, nil
+		}
+
+		// Anything but a second q cancels a pending quit.
+		if msg.String() != "q" {
+			m.quitPending = false
+		}
+
+		m.commandErr = ""
+
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		if m.commanding {
+			return m.updateCommand(msg)
+		}
+
+		// Messages state gets its own key handling before the global switch.
+		if m.State == StateMessages && !m.messageSent {
+			return m.updateMessages(msg)
+		}
+		if m.State == StateInbox {
+			next, cmd, handled := m.updateInbox(msg)
+			if handled {
+				return next, cmd
+			}
+			m = next
+		}
+		// While typing a filter the list owns every key.
+		if m.State == StateProjects && m.inProjectsList && m.projectsList.SettingFilter() {
+			var cmd tea.Cmd
+			m.projectsList, cmd = m.projectsList.Update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "o", "b", "p", "c", "m", "i", "s", "U", "w", "backspace":
+			m.rememberScroll()
+		}
+
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "q":
+			if m.quitPending {
+				return m, tea.Quit
+			}
+			m.quitPending = true
+			m.quitSeq++
+			seq := m.quitSeq
+			return m, tea.Tick(quitConfirmWindow, func(time.Time) tea.Msg { return quitTimeoutMsg{seq: seq} })
+		case "j", "down":
+			m.viewport.LineDown(1)
+		case "k", "up":
+			m.viewport.LineUp(1)
+		case "d":
+			m.viewport.LineDown(10)
+		case "u":
+			m.viewport.LineUp(10)
+		case "g":
+			m.viewport.GotoTop()
+		case "G":
+			m.viewport.GotoBottom()
+		case "o":
+			m.goHome()
+		case "backspace":
+			if m.State == StateProjects && !m.inProjectsList {
+				m.inProjectsList = true
+				m.selectedPost = nil
+			}
+		case "b":
+			m.openSection(StateBlog)
+		case "p":
+			m.openSection(StateProjects)
+		case "c":
+			m.openSection(StateContact)
+		case "m":
+			m.openMessages()
+		case "H":
+			m.travel(false)
+		case "L":
+			m.travel(true)
+		case "tab":
+			m.switchTab(1)
+		case "shift+tab":
+			m.switchTab(-1)
+		case "left", "right":
+			// The lists page with them.
+			if (m.State == StateProjects && m.inProjectsList) || (m.State == StateInbox && m.inInboxList) {
+				break
+			}
+			if msg.String() == "left" {
+				m.switchTab(-1)
+			} else {
+				m.switchTab(1)
+			}
+		case "t":
+			m.nextTheme()
+		case "s":
+			m.openSection(StateStats)
+		case "U":
+			m.openSection(StateStatus)
+		case "w":
+			m.openSection(StateGuestbook)
+		case "i":
+			if m.admin {
+				m.openInbox()
+			}
+		case ":":
+			return m, m.startCommand()
+		case "y", "Y":
+			if m.canYank() {
+				return m, m.yank(msg.String() == "Y")
+			}
+		case "/":
+			if m.canSearch() {
+				return m, m.startSearch()
+			}
+		case "n":
+			if m.canSearch() {
+				m.jumpToMatch(m.matchIdx + 1)
+			}
+		case "N":
+			if m.canSearch() {
+				m.jumpToMatch(m.matchIdx - 1)
+			}
+		case "esc":
+			if m.canSearch() {
+				m.clearSearch()
+				m.shownLink = 0
+			}
+		case "enter":
+			if m.State == StateHome {
+				if i := store.FeaturedProject(m.now(), m.projectsPosts); i >= 0 {
+					m.State = StateProjects
+					m.openProject(&m.projectsPosts[i])
+				}
+			} else if m.State == StateProjects && !m.inProjectsList && m.selectedPost != nil {
+				m.nextLink()
+			} else if m.State == StateProjects && m.inProjectsList {
+				if m.numBuf != "" {
+					m.jumpToNumber()
+				} else if i, ok := m.projectsList.SelectedItem().(store.Project); ok {
+					m.openProject(&i)
+				}
+			}
+		default:
+			// Numbers would pick from outside a filtered list.
+			if m.State == StateProjects && m.inProjectsList && !m.projectsList.IsFiltered() && isDigit(msg.String()) {
+				if cmd := m.typeDigit(msg.String()); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+		}
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
+	case quitTimeoutMsg:
+		if msg.seq == m.quitSeq {
+			m.quitPending = false
+		}
+
+	case toastTimeoutMsg:
+		if msg.seq == m.toastSeq {
+			m.toast = ""
+		}
+
+	case presenceMsg:
+		m.presence = msg.active
+		cmds = append(cmds, presenceTick())
+
+	case undoTickMsg:
+		if msg.seq == m.undoSeq && m.holding() {
+			if !m.now().Before(m.undoDeadline) {
+				return m, m.sendMessage()
+			}
+			return m, undoTick(m.undoSeq)
+		}
+
+	case sentMsg:
+		m.messageResult(msg.msg, msg.err)
+
+	case spinner.TickMsg:
+		if m.sending {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case statusTickMsg:
+		cmds = append(cmds, m.statusTicked(msg))
+
+	case numTimeoutMsg:
+		if msg.seq == m.numSeq && m.numBuf != "" {
+			m.numTimedOut()
+		}
+	}
+
+	if m.State == StateInbox && m.inInboxList {
+		var cmd tea.Cmd
+		m.inbox, cmd = m.inbox.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	// Delegate list / viewport updates when in projects.
+	if m.State == StateProjects {
+		if m.inProjectsList {
+			var cmd tea.Cmd
+			m.projectsList, cmd = m.projectsList.Update(msg)
+			cmds = append(cmds, cmd)
+		} else {
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// Cells of message text per line, in the editor and the preview box
+// alike. Both measure display width, CJK and emoji take two cells in each.
+func messageWidth(width int) int {
+	return max(width-10, 10)
+}
+
+// SetWidth counts the editor's prompt and line numbers too, this sizes
+// the text itself to messageWidth.
+func (m *Model) sizeEditor(width int) {
+	m.messageInput.SetWidth(messageWidth(width))
+	gutter := messageWidth(width) - m.messageInput.Width()
+	m.messageInput.SetWidth(messageWidth(width) + gutter)
+}
+
+// Half the window, up to the 30 columns it used to be fixed at.
+func nameInputWidth(width int) int {
+	return max(min(width/2, 30), 1)
+}
+
+// '?' is just a character while typing, so in messages it only opens
+// help when the textarea is empty.
+func (m Model) helpToggle(msg tea.KeyMsg) bool {
+	if msg.String() != "?" {
+		return false
+	}
+	if m.State == StateProjects && m.projectsList.SettingFilter() {
+		return false
+	}
+	if m.State == StateMessages && !m.messageSent {
+		return !m.editingName && m.messageInput.Value() == ""
+	}
+	return true
+}
+
+// Key events for message state only
+func (m Model) updateMessages(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.readOnly {
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "esc", "o":
+			m.goHome()
+		}
+		return m, nil
+	}
+	if m.holding() {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "u":
+			return m, m.undoSend()
+		}
+		return m, nil
+	}
+	// Nothing to edit until the send comes back.
+	if m.sending {
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+	if m.editingName {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "enter", "esc":
+			if strings.TrimSpace(m.nameInput.Value()) != "" {
+				m.username = strings.TrimSpace(m.nameInput.Value())
+				m.chosenName = m.username
+			}
+			m.editingName = false
+			m.nameInput.Blur()
+			m.messageInput.Focus()
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.nameInput, cmd = m.nameInput.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// Preview before sending, the draft stays in messageInput throughout.
+	if m.confirming {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "y", "enter":
+			m.confirming = false
+			return m, m.holdMessage()
+		case "e":
+			m.confirming = false
+			m.messageInput.Focus()
+			return m, textarea.Blink
+		case "esc":
+			m.confirming = false
+			m.messageInput.Blur()
+			m.goHome()
+		}
+		return m, nil
+	}
+
+	if next, cmd, handled := m.updateVim(msg); handled {
+		return next, cmd
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	// Leaving keeps the draft, it's only cleared by sending or ctrl+u.
+	case "esc":
+		m.messageInput.Blur()
+		m.goHome()
+		return m, nil
+	case "tab", "shift+tab":
+		m.messageInput.Blur()
+		if msg.String() == "tab" {
+			m.switchTab(1)
+		} else {
+			m.switchTab(-1)
+		}
+		return m, nil
+	case "ctrl+u":
+		m.resetMessage()
+		return m, nil
+	case "ctrl+r":
+		if m.draft != "" && m.messageInput.Value() == "" {
+			m.messageInput.SetValue(m.draft)
+			m.draft = ""
+		}
+		return m, nil
+	case "ctrl+n":
+		m.editingName = true
+		m.nameInput.SetValue(m.username)
+		m.nameInput.Focus()
+		m.messageInput.Blur()
+		return m, textinput.Blink
+	case "ctrl+g":
+		m.public = GuestbookEnabled && !m.public
+		return m, nil
+	case "ctrl+p":
+		m.showReceipt = !m.showReceipt
+		return m, nil
+	case "ctrl+s":
+		content := strings.TrimSpace(m.messageInput.Value())
+		switch err := store.Validate(m.username, content); {
+		case errors.Is(err, store.ErrMessageEmpty):
+		case errors.Is(err, store.ErrMessageTooLong):
+			log.Infof("Message too long: %s", content)
+			m.tooLong = true
+		case err != nil:
+			m.sendErr = err
+		default:
+			m.tooLong = false
+			m.confirming = true
+			m.messageInput.Blur()
+		}
+		return m, nil
+	default:
+		m.sendErr = nil
+		m.tooLong = false
+		var cmd tea.Cmd
+		m.messageInput, cmd = m.messageInput.Update(msg)
+		return m, cmd
+	}
+}
+
+// Holds the confirmed message for undoWindow before it's sent, 'u'
+// takes it back. The draft stays in messageInput until then.
+func (m *Model) holdMessage() tea.Cmd {
+	user, content, remote, fp := m.username, strings.TrimSpace(m.messageInput.Value()), m.remoteAddr, m.fingerprint
+	public := m.public
+	m.pending.hold(func() (store.Message, error) {
+		msg, err := store.AddMessage(user, content, remote, fp)
+		if err == nil && public {
+			// Still sent privately if it can't be published.
+			if p, err := store.Publish(msg); err == nil {
+				msg = p
+			}
+		}
+		return msg, err
+	})
+	m.undoDeadline = m.now().Add(undoWindow)
+	m.undoSeq++
+	m.messageInput.Blur()
+	return undoTick(m.undoSeq)
+}
+
+func (m Model) holding() bool {
+	return !m.undoDeadline.IsZero()
+}
+
+func (m *Model) undoSend() tea.Cmd {
+	m.pending.take()
+	m.undoDeadline = time.Time{}
+	m.messageInput.Focus()
+	return textarea.Blink
+}
+
+// Hands the held message to the server off the update loop, the spinner
+// runs until sentMsg comes back.
+func (m *Model) sendMessage() tea.Cmd {
+	m.undoDeadline = time.Time{}
+	send := m.pending.take()
+	if send == nil {
+		return nil
+	}
+	m.sending = true
+	m.sendErr = nil
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		msg, err := send()
+		return sentMsg{msg: msg, err: err}
+	})
+}
+
+type sentMsg struct {
+	msg store.Message
+	err error
+}
+
+func (m *Model) messageResult(msg store.Message, err error) {
+	m.sending = false
+	// The draft stays in the editor to fix or send again.
+	if err != nil {
+		m.messageInput.Focus()
+		log.Error("Could not save message", "error", err, "user", m.username, "remote", m.remoteAddr)
+		switch {
+		case errors.Is(err, store.ErrMessageTooLong):
+			m.tooLong = true
+		default:
+			m.sendErr = err
+		}
+		return
+	}
+	log.Info("Message submitted", "user", m.username, "remote", m.remoteAddr, "fingerprint", m.fingerprint)
+	m.messageInput.Reset()
+	m.messageSent = true
+	m.tooLong = false
+	m.sent = msg
+	m.queuePos = store.QueuePosition(msg)
+	m.sentPublic = m.public
+	m.public = false
+}
+
+// Empties the editor, keeping what was in it for ctrl+r.
+func (m *Model) resetMessage() {
+	if strings.TrimSpace(m.messageInput.Value()) != "" {
+		m.draft = m.messageInput.Value()
+	}
+	m.messageInput.Reset()
+}
@@ -0,0 +1,310 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// A failed send leaves the draft in the editor with the reason above it.
+func TestSendErrorKeepsDraft(t *testing.T) {
+	for _, tt := range []struct {
+		err    error
+		notice string
+	}{
+		{store.ErrRateLimited, "please wait a few minutes"},
+		{store.ErrMailboxFull, "The mailbox is full"},
+		{store.ErrMessageTooLong, "too long"},
+		{errors.New("disk on fire"), "something went wrong"},
+	} {
+		m := newTestModel(t, 100, 30)
+		m = press(m, "m")
+		m = typeText(m, "keep me")
+		m = press(m, "ctrl+s", "y")
+		// As if the countdown ran out and the send came back failed.
+		m.pending.take()
+		m.sending, m.undoDeadline = true, time.Time{}
+
+		m = send(m, sentMsg{err: tt.err})
+		if got := m.messageInput.Value(); got != "keep me" {
+			t.Errorf("%v: editor = %q, want the draft kept", tt.err, got)
+		}
+		if !m.messageInput.Focused() {
+			t.Errorf("%v: editor isn't focused to fix the draft", tt.err)
+		}
+		view := m.View()
+		if !strings.Contains(view, tt.notice) || !strings.Contains(view, "keep me") {
+			t.Errorf("%v: view doesn't show the error and the draft:\n%s", tt.err, view)
+		}
+
+		m = typeText(m, "!")
+		if m.sendErr != nil || m.tooLong || strings.Contains(m.View(), tt.notice) {
+			t.Errorf("%v: typing didn't clear the error", tt.err)
+		}
+		if got := m.messageInput.Value(); got != "keep me!" {
+			t.Errorf("%v: editor = %q after typing", tt.err, got)
+		}
+	}
+}
+
+func TestTooLongKeepsDraft(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "line")
+	for range 10 {
+		m = press(m, "enter")
+		m = typeText(m, "line")
+	}
+	draft := m.messageInput.Value()
+
+	m = press(m, "ctrl+s")
+	if m.confirming || !m.tooLong {
+		t.Fatalf("confirming %v, too long %v; want the too long notice", m.confirming, m.tooLong)
+	}
+	if got := m.messageInput.Value(); got != draft {
+		t.Errorf("editor = %q, want the draft kept", got)
+	}
+	if !strings.Contains(m.View(), "too long") {
+		t.Error("no too long notice")
+	}
+
+	m = press(m, "backspace", "backspace", "backspace", "backspace", "backspace", "ctrl+s")
+	if !m.confirming {
+		t.Fatal("trimming the draft didn't let it through")
+	}
+}
+
+func TestUnprintableName(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m.username = "bad\x1b[2Jname"
+	m = press(m, "m")
+	m = typeText(m, "hi")
+	m = press(m, "ctrl+s")
+	if m.confirming || !errors.Is(m.sendErr, store.ErrUnprintable) {
+		t.Fatalf("confirming %v, sendErr %v; want ErrUnprintable", m.confirming, m.sendErr)
+	}
+	if m.messageInput.Value() != "hi" {
+		t.Errorf("editor = %q, want the draft kept", m.messageInput.Value())
+	}
+}
+
+// Bouncing between the editor and the preview never loses the draft.
+func TestPreviewEditRoundTrip(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "first line")
+	m = press(m, "enter")
+	m = typeText(m, "second line")
+	draft := m.messageInput.Value()
+
+	m = press(m, "ctrl+s")
+	if !m.confirming || m.messageInput.Focused() {
+		t.Fatalf("ctrl+s: confirming %v, editor focused %v", m.confirming, m.messageInput.Focused())
+	}
+	view := m.View()
+	for _, want := range []string{"first line", "second line", "tester"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("preview doesn't show %q", want)
+		}
+	}
+
+	m = press(m, "e")
+	if m.confirming || !m.messageInput.Focused() || m.State != StateMessages {
+		t.Fatalf("e: confirming %v, editor focused %v, state %v", m.confirming, m.messageInput.Focused(), m.State)
+	}
+	if m.messageInput.Value() != draft {
+		t.Fatalf("e: editor %q, want %q", m.messageInput.Value(), draft)
+	}
+	// Editing carries on where the cursor was, at the end.
+	m = typeText(m, ", edited")
+	draft += ", edited"
+	if m.messageInput.Value() != draft {
+		t.Fatalf("after editing: %q, want %q", m.messageInput.Value(), draft)
+	}
+
+	m = press(m, "ctrl+s")
+	if !m.confirming || !strings.Contains(m.View(), "second line, edited") {
+		t.Fatal("second preview doesn't show the edit")
+	}
+
+	m = press(m, "esc")
+	if m.confirming || m.State != StateHome || m.holding() {
+		t.Fatalf("esc: confirming %v, state %v, holding %v", m.confirming, m.State, m.holding())
+	}
+	m = press(m, "m")
+	if m.messageInput.Value() != draft {
+		t.Fatalf("back after esc: %q, want %q", m.messageInput.Value(), draft)
+	}
+
+	m = press(m, "ctrl+s", "enter")
+	if m.confirming || !m.holding() {
+		t.Fatalf("enter: confirming %v, holding %v", m.confirming, m.holding())
+	}
+	send := m.pending.take()
+	if send == nil {
+		t.Fatal("nothing held")
+	}
+	msg, err := send()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DeleteMessage(msg) })
+	if msg.Content != draft {
+		t.Errorf("sent %q, want %q", msg.Content, draft)
+	}
+}
+
+// Only y, enter, e, esc and ctrl+c do anything in the preview, nothing
+// typed there reaches the draft.
+func TestPreviewIgnoresOtherKeys(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "hold still")
+	m = press(m, "ctrl+s")
+	for _, k := range []string{"x", "q", "o", "tab", "backspace", "ctrl+u", "ctrl+n", "u"} {
+		m = press(m, k)
+		if !m.confirming || m.State != StateMessages || m.editingName || m.messageInput.Value() != "hold still" {
+			t.Fatalf("%s in the preview: confirming %v, state %v, editing name %v, draft %q",
+				k, m.confirming, m.State, m.editingName, m.messageInput.Value())
+		}
+	}
+
+	m = send(m, tea.WindowSizeMsg{Width: 80, Height: 24})
+	if !m.confirming || m.messageInput.Focused() {
+		t.Errorf("resize in the preview: confirming %v, editor focused %v", m.confirming, m.messageInput.Focused())
+	}
+}
+
+func TestPreviewNeedsAMessage(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "   ")
+	m = press(m, "ctrl+s")
+	if m.confirming {
+		t.Error("blank message previewed")
+	}
+	if !m.messageInput.Focused() {
+		t.Error("editor lost focus on a blank ctrl+s")
+	}
+}
+
+// A resize mid-edit keeps whichever input was being typed in focused,
+// with its text and cursor where they were.
+func TestResizeMidNameEdit(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "the draft")
+	m = press(m, "ctrl+n", "ctrl+u")
+	m = typeText(m, "new name")
+	m = press(m, "left", "left")
+	if !m.editingName || m.nameInput.Position() != len("new na") {
+		t.Fatalf("setup: editing %v, cursor %d", m.editingName, m.nameInput.Position())
+	}
+
+	for _, size := range []tea.WindowSizeMsg{{Width: 60, Height: 20}, {Width: 140, Height: 50}, {Width: 100, Height: 30}} {
+		m = send(m, size)
+		if !m.nameInput.Focused() || m.messageInput.Focused() {
+			t.Fatalf("%dx%d: name focused %v, editor focused %v", size.Width, size.Height, m.nameInput.Focused(), m.messageInput.Focused())
+		}
+		if m.nameInput.Value() != "new name" || m.nameInput.Position() != len("new na") {
+			t.Fatalf("%dx%d: name %q, cursor %d", size.Width, size.Height, m.nameInput.Value(), m.nameInput.Position())
+		}
+		if m.nameInput.Width != nameInputWidth(size.Width) {
+			t.Errorf("%dx%d: name input %d wide, want %d", size.Width, size.Height, m.nameInput.Width, nameInputWidth(size.Width))
+		}
+		fitsWindow(t, fmt.Sprintf("name edit at %d columns", size.Width), m.View(), size.Width)
+	}
+
+	// Typing carries on at the cursor.
+	m = typeText(m, "X")
+	if m.nameInput.Value() != "new naXme" {
+		t.Errorf("typed after resizes: %q", m.nameInput.Value())
+	}
+	if m.messageInput.Value() != "the draft" {
+		t.Errorf("draft %q after editing the name", m.messageInput.Value())
+	}
+	m = press(m, "enter")
+	if m.editingName || !m.messageInput.Focused() {
+		t.Errorf("enter: editing name %v, editor focused %v", m.editingName, m.messageInput.Focused())
+	}
+}
+
+func TestResizeKeepsEditorCursor(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "abcdef")
+	m = press(m, "left", "left")
+	m = send(m, tea.WindowSizeMsg{Width: 70, Height: 24})
+	if !m.messageInput.Focused() {
+		t.Fatal("editor lost focus on resize")
+	}
+	m = typeText(m, "X")
+	if m.messageInput.Value() != "abcdXef" {
+		t.Errorf("typed after a resize: %q", m.messageInput.Value())
+	}
+}
+
+// Confirms the draft and lets the undo countdown run out.
+func sendDraft(t *testing.T, m Model) (Model, store.Message) {
+	t.Helper()
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	m = press(m, "ctrl+s", "y")
+	now = now.Add(undoWindow)
+	next, cmd := m.Update(undoTickMsg{seq: m.undoSeq})
+	m = next.(Model)
+	for _, msg := range run(cmd) {
+		if s, ok := msg.(sentMsg); ok {
+			if s.err != nil {
+				t.Fatalf("send failed: %v", s.err)
+			}
+			t.Cleanup(func() {
+				store.DeleteMessage(s.msg)
+				store.DeleteGuestbookEntry(s.msg)
+			})
+			return send(m, s), s.msg
+		}
+	}
+	t.Fatal("sending produced no sentMsg")
+	return m, store.Message{}
+}
+
+// Asking for the guestbook with a link in the message still sends it,
+// privately, and says so.
+func TestPublishRejectedShown(t *testing.T) {
+	saved := GuestbookEnabled
+	GuestbookEnabled = true
+	t.Cleanup(func() { GuestbookEnabled = saved })
+
+	for _, tt := range []struct {
+		content, want string
+		public        bool
+	}{
+		{"see www.example.com " + t.Name(), "Links aren't allowed in the guestbook", false},
+		{"no links here " + t.Name(), "It'll show in the guestbook once I've approved it", true},
+	} {
+		m := typeText(press(newTestModel(t, 100, 30), "m"), tt.content)
+		m = press(m, "ctrl+g")
+		m, msg := sendDraft(t, m)
+		if msg.Public != tt.public {
+			t.Errorf("%q: public %v, want %v", tt.content, msg.Public, tt.public)
+		}
+		if view := ansi.Strip(m.View()); !strings.Contains(view, tt.want) {
+			t.Errorf("%q: sent view doesn't say %q:\n%s", tt.content, tt.want, view)
+		}
+		if m.public {
+			t.Errorf("%q: guestbook still asked for after sending", tt.content)
+		}
+	}
+
+	m, _ := sendDraft(t, typeText(press(newTestModel(t, 100, 30), "m"), "private www.example.com "+t.Name()))
+	if view := ansi.Strip(m.View()); strings.Contains(view, "guestbook") {
+		t.Errorf("private message mentions the guestbook:\n%s", view)
+	}
+}
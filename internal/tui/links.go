@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Full URLs plus bare host/path links like github.com/will-x86.
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+|\b(?:[a-zA-Z0-9-]+\.)+(?:com|org|net|io|dev|sh|co\.uk)(?:/[^\s<>"]*)?`)
+
+// Existing escape sequences are left untouched, links are only added
+// in the plain text between them.
+var escapeSeq = regexp.MustCompile(`\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// Wraps URLs in OSC 8 hyperlinks so supporting terminals make them
+// clickable. Returns s unchanged when enabled is false, e.g. for dumb
+// terminals, or when s already carries its own hyperlinks.
+func hyperlinks(s string, enabled bool) string {
+	if !enabled || strings.Contains(s, "\x1b]8;") {
+		return s
+	}
+
+	return mapText(s, linkify)
+}
+
+// Applies f to the plain text between escape sequences.
+func mapText(s string, f func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range escapeSeq.FindAllStringIndex(s, -1) {
+		b.WriteString(f(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(f(s[last:]))
+	return b.String()
+}
+
+// A link in some text, start and end cover it without any trailing
+// punctuation.
+type link struct {
+	start, end int
+	target     string
+}
+
+func findLinks(s string) []link {
+	var links []link
+	for _, loc := range urlPattern.FindAllStringIndex(s, -1) {
+		// The domain half of an email isn't a link.
+		if loc[0] > 0 && s[loc[0]-1] == '@' {
+			continue
+		}
+		// Sentence punctuation after a link isn't part of it.
+		trimmed := strings.TrimRight(s[loc[0]:loc[1]], ".,;:!?)'")
+		target := trimmed
+		if !strings.Contains(target, "://") {
+			target = "https://" + target
+		}
+		links = append(links, link{start: loc[0], end: loc[0] + len(trimmed), target: target})
+	}
+	return links
+}
+
+func linkify(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, l := range findLinks(s) {
+		b.WriteString(s[last:l.start])
+		b.WriteString("\x1b]8;;" + l.target + "\x1b\\" + s[l.start:l.end] + "\x1b]8;;\x1b\\")
+		last = l.end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// Where p's links go, its URL: first, without repeats.
+func projectLinks(p store.Project) []string {
+	var targets []string
+	seen := map[string]bool{}
+	for _, l := range findLinks(p.Detail()) {
+		if !seen[l.target] {
+			seen[l.target] = true
+			targets = append(targets, l.target)
+		}
+	}
+	return targets
+}
+
+// Shows the open project's next link in the footer, where it can be
+// copied from terminals without OSC 8, and scrolls to it.
+func (m *Model) nextLink() {
+	links := projectLinks(*m.selectedPost)
+	if len(links) == 0 {
+		return
+	}
+	m.shownLink = m.shownLink%len(links) + 1
+	target := strings.TrimPrefix(links[m.shownLink-1], "https://")
+	wrapped := wrapLines(m.viewportRaw, m.viewport.Width-m.viewport.Style.GetHorizontalFrameSize())
+	if lines := matchLines(wrapped, target); len(lines) > 0 {
+		m.viewport.SetYOffset(lines[0])
+	}
+}
+
+func (m Model) linkFooter() string {
+	links := projectLinks(*m.selectedPost)
+	if m.shownLink > len(links) {
+		return ""
+	}
+	label := fmt.Sprintf("Link %d/%d: ", m.shownLink, len(links))
+	hint := m.help.ShortHelpView([]key.Binding{keys.NextLink, keys.HideLink})
+	// Cut to fit the footer beside the scroll position, the link keeps
+	// pointing at the whole URL.
+	room := m.width - len(label) - len(" • ") - ansi.StringWidth(hint) - ansi.StringWidth(m.scrollIndicator()) - 1
+	u := ansi.Truncate(links[m.shownLink-1], max(room, 10), "…")
+	if m.linksEnabled() {
+		u = "\x1b]8;;" + links[m.shownLink-1] + "\x1b\\" + u + "\x1b]8;;\x1b\\"
+	}
+	return m.QuitStyle.Render(label) + u + m.QuitStyle.Render(" • ") + hint
+}
+
+// Terminals reporting no color support get plain text.
+func (m Model) linksEnabled() bool {
+	return m.profile != "Ascii"
+}
@@ -0,0 +1,339 @@
+package tui
+
+import (
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish/bubbletea"
+	sshserver "github.com/will-x86/ssh-will-x86/internal/ssh"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+	"github.com/will-x86/ssh-will-x86/internal/webterm"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	HeaderHeight = 1
+	FooterHeight = 1
+
+	// Smallest window the layout works in.
+	MinWidth  = 60
+	MinHeight = 15
+)
+
+// Characters per line on the thermal printer, for the receipt preview.
+var ReceiptWidth = 32
+
+type Model struct {
+	term        string
+	remoteAddr  string
+	fingerprint string // empty unless they logged in with a key
+	State       State
+	profile     string
+	width       int
+	height      int
+	bg          string
+	renderer    *lipgloss.Renderer // the session's, styles have to come from it
+	theme       int                // index into Themes
+	TxtStyle    lipgloss.Style
+	QuitStyle   lipgloss.Style
+	MatchStyle  lipgloss.Style // search hits in the viewport
+	HeaderStyle lipgloss.Style
+	BannerStyle lipgloss.Style
+	TabStyle    lipgloss.Style // the open section's tab in the header
+
+	viewport    viewport.Model
+	viewportRaw string // viewport content before wrapping and highlighting
+	content     string
+	tooLong     bool
+
+	projectsPosts  []store.Project
+	selectedPost   *store.Project
+	inProjectsList bool
+	projectsList   list.Model
+	numBuf         string      // digits typed so far for a project number
+	numSeq         int         // bumps per digit so stale timeouts are ignored
+	numAt          time.Time   // when the last digit was typed, by m.now
+	scrollPos      map[int]int // top line per ProjectNumber, see topLine
+	shownLink      int         // 1-based link of the open project shown in the footer, 0 for none
+
+	back, forward []visit // views H and L return to, most recent last
+	travelled     bool    // this update moved through them, see recordVisit
+
+	searchInput textinput.Model
+	searching   bool   // typing a '/' query
+	query       string // last search, highlighted until esc
+	matches     []int  // wrapped viewport lines containing query
+	matchIdx    int
+
+	commandInput textinput.Model
+	commanding   bool   // typing a ':' command
+	commandErr   string // what was wrong with the last one, until the next key
+
+	admin        bool // owner's user and key, can open the inbox
+	inbox        list.Model
+	inInboxList  bool
+	inQuarantine bool // the inbox lists what the filters caught instead
+	openMessage  *store.Message
+
+	messageInput textarea.Model
+	nameInput    textinput.Model
+	username     string
+	chosenName   string // set with ctrl+n, remembered for key logins
+	editingName  bool
+	messageSent  bool
+	confirming   bool // previewing the message before it's sent
+	showReceipt  bool // draft shown wrapped like the printout
+	public       bool // ctrl+g, also put the message in the guestbook
+	sentPublic   bool // public was set for the last message sent, see sentDetails
+	pending      *pendingSend
+	undoDeadline time.Time // when the held message is sent, zero if none
+	undoSeq      int
+	sending      bool  // waiting on AddMessage, the spinner is shown
+	sendErr      error // AddMessage failed for another reason, e.g. rate limited
+	spinner      spinner.Model
+	sent         store.Message // last message sent this session
+	queuePos     int           // sent's place in the print queue when it went in
+	vimNormal    bool          // -vim-input normal mode, typing goes to the editor otherwise
+	vimPending   string        // first d of dd
+	draft        string        // last message cleared without sending (ctrl+u), ctrl+r brings it back
+	readOnly     bool          // browser terminal that didn't pass the challenge
+
+	help     help.Model
+	showHelp bool
+
+	quitPending bool // first q pressed, waiting for the second
+	quitSeq     int
+
+	out      io.Writer // the program's output, for OSC 52 copies, see sessionOutput
+	toast    string    // brief note in the footer, e.g. after a copy
+	toastSeq int
+
+	now           func() time.Time
+	startedAt     time.Time
+	lastActivity  time.Time
+	disconnecting string // goodbye shown before an idle/max-duration close
+	presence      int64  // sessions open, shown in the header
+	statusSeq     int    // bumps each time the status page opens, see statusCmd
+}
+
+// What a Model needs to know about its session, kept apart from
+// ssh.Session so a Model can be built (and driven through Update)
+// without a connection.
+type Visitor struct {
+	Term        string
+	RemoteAddr  string
+	Username    string // who messages are from
+	Fingerprint string // empty unless they logged in with a key
+	Width       int
+	Height      int
+	ReadOnly    bool // browser terminal that didn't pass the challenge
+	Admin       bool
+}
+
+// Creates the program for each ssh session. Its output is a
+// sessionOutput shared with OSC 52 copies, see yank.
+func NewTeaHandler() bubbletea.ProgramHandler {
+	return func(s ssh.Session) *tea.Program {
+		store.SessionStarted()
+		go func() {
+			<-s.Context().Done()
+			store.SessionEnded()
+		}()
+
+		pty, _, _ := s.Pty()
+		log.Info("New session", "user", s.User(), "remote", s.RemoteAddr().String(),
+			"term", pty.Term, "width", pty.Window.Width, "height", pty.Window.Height)
+
+		v := visitorFromSession(s, pty)
+		visitor := v.Fingerprint
+		if visitor == "" {
+			visitor, _, _ = net.SplitHostPort(v.RemoteAddr)
+		}
+		store.RecordSession(visitor)
+		store.RecordView(StateHome.String())
+
+		m := NewModel(v, bubbletea.MakeRenderer(s))
+		out := &sessionOutput{w: s}
+		m.out = out
+		go func() {
+			<-s.Context().Done()
+			m.commitPending()
+		}()
+		// The server emulates the pty, so MakeOptions' output is the
+		// session and this takes its place.
+		opts := append(bubbletea.MakeOptions(s), tea.WithOutput(out), tea.WithAltScreen(), tea.WithMouseCellMotion())
+		return tea.NewProgram(m, opts...)
+	}
+}
+
+func visitorFromSession(s ssh.Session, pty ssh.Pty) Visitor {
+	v := Visitor{
+		Term:       pty.Term,
+		RemoteAddr: s.RemoteAddr().String(),
+		Username:   s.User(),
+		Width:      pty.Window.Width,
+		Height:     pty.Window.Height,
+	}
+	if k, ok := sshserver.AuthorizedKeyFrom(s.Context()); ok && k.Comment != "" {
+		v.Username = k.Comment
+	}
+	// Usernames are whatever the client sent, they're printed and shown
+	// in the inbox.
+	v.Username = store.CleanName(v.Username)
+	if v.Username == "" {
+		v.Username = "anonymous"
+	}
+	if pk := s.PublicKey(); pk != nil {
+		v.Fingerprint = gossh.FingerprintSHA256(pk)
+	}
+
+	for _, env := range s.Environ() {
+		if env == webterm.EnvMode+"="+webterm.ModeReadOnly {
+			v.ReadOnly = true
+		}
+		// Browser sessions all share the bridge's throwaway key.
+		if strings.HasPrefix(env, webterm.EnvMode+"=") {
+			v.Fingerprint = ""
+		}
+	}
+	v.Admin = v.Fingerprint != "" && isAdmin(s.User(), s.PublicKey())
+	return v
+}
+
+// Builds the model for one visitor, styles come from renderer so they
+// match the visitor's terminal.
+func NewModel(v Visitor, renderer *lipgloss.Renderer) Model {
+	contentHeight := max(v.Height-HeaderHeight-FooterHeight, 0)
+	width := max(v.Width, 0)
+
+	projectsPosts, err := store.Projects()
+	if err != nil {
+		log.Error("Failed to load projects", "error", err)
+		projectsPosts = []store.Project{}
+	}
+
+	delegate := list.NewDefaultDelegate()
+	projectsList := list.New(projectItems(projectsPosts), delegate, width, max(contentHeight-2, 0))
+	projectsList.SetShowHelp(false)
+	projectsList.SetShowTitle(false)
+	projectsList.SetFilteringEnabled(true)
+	// Quitting goes through our q confirmation, not the list's q/esc.
+	projectsList.KeyMap.Quit.SetEnabled(false)
+	// Paging is on h/l, the list's b/u/d/f defaults are our own keys.
+	projectsList.KeyMap.PrevPage.SetKeys("left", "h", "pgup")
+	projectsList.KeyMap.NextPage.SetKeys("right", "l", "pgdown")
+	projectsList.SetShowPagination(false)
+	projectsList.Styles.PaginationStyle = lipgloss.NewStyle()
+
+	bg := "light"
+	if renderer.HasDarkBackground() {
+		bg = "dark"
+	}
+
+	vp := viewport.New(width, contentHeight)
+
+	ta := textarea.New()
+	ta.Placeholder = "Type your message here..."
+	ta.Focus()
+	ta.SetHeight(5)
+	ta.CharLimit = store.MaxMessageLength
+
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
+
+	commandInput := textinput.New()
+	commandInput.Prompt = ":"
+
+	nameInput := textinput.New()
+	nameInput.Placeholder = "Your name"
+	nameInput.Width = nameInputWidth(width)
+	nameInput.CharLimit = store.MaxNameLength
+
+	m := Model{
+		term:           v.Term,
+		remoteAddr:     v.RemoteAddr,
+		fingerprint:    v.Fingerprint,
+		profile:        renderer.ColorProfile().Name(),
+		width:          v.Width,
+		height:         v.Height,
+		bg:             bg,
+		renderer:       renderer,
+		viewport:       vp,
+		content:        "",
+		projectsPosts:  projectsPosts,
+		inProjectsList: true,
+		projectsList:   projectsList,
+		scrollPos:      map[int]int{},
+		searchInput:    searchInput,
+		commandInput:   commandInput,
+		admin:          v.Admin,
+		inbox:          newInboxList(width, max(contentHeight-2, 0)),
+		messageInput:   ta,
+		nameInput:      nameInput,
+		spinner:        spinner.New(spinner.WithSpinner(spinner.Dot)),
+		pending:        &pendingSend{},
+		username:       v.Username,
+		editingName:    false,
+		readOnly:       v.ReadOnly,
+		help:           help.New(),
+		now:            time.Now,
+		startedAt:      time.Now(),
+		lastActivity:   time.Now(),
+		presence:       store.ActiveSessions(),
+	}
+	m.sizeEditor(width)
+	m.applyTheme(startTheme(renderer))
+	m.goHome()
+	if v.Fingerprint != "" {
+		if p, ok := store.PrefsFor(v.Fingerprint); ok {
+			m.restorePrefs(p)
+		}
+	}
+	return m
+}
+
+func projectItems(posts []store.Project) []list.Item {
+	items := make([]list.Item, len(posts))
+	for i, post := range posts {
+		items[i] = post
+	}
+	return items
+}
+
+// Picks up projects reloaded since the list was built, for when the
+// section is opened again. Reloads swap the cached slice whole, so a new
+// first element means new projects.
+func (m *Model) refreshProjects() {
+	posts, err := store.Projects()
+	if err != nil || (len(posts) == len(m.projectsPosts) && (len(posts) == 0 || &posts[0] == &m.projectsPosts[0])) {
+		return
+	}
+	m.projectsPosts = posts
+	m.projectsList.SetItems(projectItems(posts))
+	m.rebaseHistory()
+}
+
+func (m Model) tooSmall() bool {
+	return m.width < MinWidth || m.height < MinHeight
+}
+
+func (m Model) Init() tea.Cmd {
+	cmds := []tea.Cmd{textarea.Blink, idleCheck(), presenceTick()}
+	// Started with the mouse on, let go of it if the first screen fits.
+	if !m.wantsMouse() {
+		cmds = append(cmds, tea.DisableMouse)
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,314 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Runs from the repo root so projects.txt and content/ load like they do
+// for the server.
+func TestMain(m *testing.M) {
+	if err := os.Chdir("../.."); err != nil {
+		panic(err)
+	}
+	if err := LoadPages("content"); err != nil {
+		panic(err)
+	}
+	// Tests send the same text every run, -count would trip the duplicate
+	// filter.
+	store.MessageRate, store.DuplicateWindow = 0, 0
+	os.Exit(m.Run())
+}
+
+// A Model for a visitor on a width by height terminal with no colours.
+func newTestModel(t *testing.T, width, height int) Model {
+	t.Helper()
+	return NewModel(Visitor{
+		Term:       "xterm-256color",
+		RemoteAddr: "192.0.2.1:1234",
+		Username:   "tester",
+		Width:      width,
+		Height:     height,
+	}, lipgloss.NewRenderer(io.Discard))
+}
+
+var namedKeys = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"backspace": tea.KeyBackspace,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"ctrl+c":    tea.KeyCtrlC,
+	"ctrl+g":    tea.KeyCtrlG,
+	"ctrl+n":    tea.KeyCtrlN,
+	"ctrl+p":    tea.KeyCtrlP,
+	"ctrl+r":    tea.KeyCtrlR,
+	"ctrl+s":    tea.KeyCtrlS,
+	"ctrl+u":    tea.KeyCtrlU,
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	if t, ok := namedKeys[k]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// Sends each key through Update, dropping the commands.
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		m = send(m, keyMsg(k))
+	}
+	return m
+}
+
+// Types text a rune at a time.
+func typeText(m Model, text string) Model {
+	for _, r := range text {
+		m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func send(m Model, msg tea.Msg) Model {
+	next, _ := m.Update(msg)
+	return next.(Model)
+}
+
+// Messages cmd produces, batches flattened. Commands that don't return
+// quickly (ticks) are dropped.
+func run(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			var msgs []tea.Msg
+			for _, c := range batch {
+				msgs = append(msgs, run(c)...)
+			}
+			return msgs
+		}
+		return []tea.Msg{msg}
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestProjectsRoundTrip(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	if m.State != StateHome {
+		t.Fatalf("starts in %v, want home", m.State)
+	}
+
+	m = press(m, "p")
+	if m.State != StateProjects || !m.inProjectsList {
+		t.Fatalf("after p: state %v, in list %v", m.State, m.inProjectsList)
+	}
+	want, ok := m.projectsList.SelectedItem().(store.Project)
+	if !ok {
+		t.Fatal("no project selected in the list")
+	}
+
+	m = press(m, "enter")
+	if m.inProjectsList || m.selectedPost == nil {
+		t.Fatal("enter didn't open the selected project")
+	}
+	if m.selectedPost.Title() != want.Title() {
+		t.Errorf("opened %q, want %q", m.selectedPost.Title(), want.Title())
+	}
+	if !strings.Contains(m.View(), m.selectedPost.ProjectTitle) {
+		t.Error("project detail doesn't show its title")
+	}
+
+	m = press(m, "backspace")
+	if !m.inProjectsList || m.selectedPost != nil {
+		t.Fatal("backspace didn't return to the list")
+	}
+
+	m = press(m, "o")
+	if m.State != StateHome {
+		t.Fatalf("after o: state %v, want home", m.State)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	m := newTestModel(t, 100, 30)
+	m.now = func() time.Time { return now }
+
+	m = press(m, "m")
+	if m.State != StateMessages || !m.messageInput.Focused() {
+		t.Fatalf("after m: state %v, editor focused %v", m.State, m.messageInput.Focused())
+	}
+	m = typeText(m, "hello from the tests")
+	m = press(m, "ctrl+s")
+	if !m.confirming {
+		t.Fatal("ctrl+s didn't show the preview")
+	}
+	if !strings.Contains(m.View(), "hello from the tests") {
+		t.Error("preview doesn't show the message")
+	}
+
+	m = press(m, "y")
+	if !m.holding() {
+		t.Fatal("confirming didn't start the undo countdown")
+	}
+
+	now = now.Add(undoWindow)
+	next, cmd := m.Update(undoTickMsg{seq: m.undoSeq})
+	m = next.(Model)
+	if !m.sending {
+		t.Fatal("the countdown ending didn't send")
+	}
+	var sent *sentMsg
+	for _, msg := range run(cmd) {
+		if s, ok := msg.(sentMsg); ok {
+			sent = &s
+		}
+	}
+	if sent == nil {
+		t.Fatal("sending produced no sentMsg")
+	}
+	if sent.err != nil {
+		t.Fatalf("send failed: %v", sent.err)
+	}
+	t.Cleanup(func() { store.DeleteMessage(sent.msg) })
+
+	m = send(m, *sent)
+	if !m.messageSent || m.messageInput.Value() != "" {
+		t.Fatalf("after sending: sent %v, editor %q", m.messageSent, m.messageInput.Value())
+	}
+	if !strings.Contains(m.View(), "Thank you for your message") {
+		t.Error("no thank you after sending")
+	}
+	if sent.msg.From != "tester" || sent.msg.Content != "hello from the tests" {
+		t.Errorf("sent %+v", sent.msg)
+	}
+
+	m = press(m, "o")
+	if m.State != StateHome {
+		t.Fatalf("after o: state %v, want home", m.State)
+	}
+}
+
+func TestUndoSend(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "take it back")
+	m = press(m, "ctrl+s", "y", "u")
+	if m.holding() || m.pending.take() != nil {
+		t.Fatal("u didn't take the message back")
+	}
+	if m.messageInput.Value() != "take it back" {
+		t.Errorf("editor = %q after undo, want the draft kept", m.messageInput.Value())
+	}
+}
+
+// Home's featured project follows the model's clock, and Enter opens the
+// one it shows.
+func TestFeaturedProjectUsesModelClock(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	if len(m.projectsPosts) < 2 {
+		t.Skip("needs at least two projects")
+	}
+	day := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	// A day whose pick differs from day's, so the test can't pass on
+	// the wall clock by chance.
+	other := day
+	for store.FeaturedProject(other, m.projectsPosts) == store.FeaturedProject(day, m.projectsPosts) {
+		other = other.AddDate(0, 0, 1)
+	}
+	for _, now := range []time.Time{day, other} {
+		m.now = func() time.Time { return now }
+		m.goHome()
+		want := m.projectsPosts[store.FeaturedProject(now, m.projectsPosts)]
+		if !strings.Contains(m.viewportRaw, fmt.Sprintf("#%d — %s", want.ProjectNumber, want.ProjectTitle)) {
+			t.Errorf("%s: home doesn't feature #%d", now.Format(store.DateLayout), want.ProjectNumber)
+		}
+		opened := press(m, "enter")
+		if opened.State != StateProjects || opened.selectedPost == nil || opened.selectedPost.ProjectNumber != want.ProjectNumber {
+			t.Errorf("%s: enter opened %v, want #%d", now.Format(store.DateLayout), opened.selectedPost, want.ProjectNumber)
+		}
+	}
+}
+
+// Enter on a filtered list opens the highlighted project, not whatever
+// sat at that index before filtering.
+func TestFilterThenOpen(t *testing.T) {
+	for _, query := range []string{"lambda", "STM32", "printing"} {
+		m := newTestModel(t, 100, 30)
+		m = press(m, "p", "/")
+		m = typeText(m, query[:len(query)-1])
+		m = filterKey(m, query[len(query)-1:])
+		m = press(m, "enter")
+		if !m.projectsList.IsFiltered() {
+			t.Fatalf("%s: list not filtered", query)
+		}
+		want, ok := m.projectsList.SelectedItem().(store.Project)
+		if !ok {
+			t.Fatalf("%s: nothing matched", query)
+		}
+		if !strings.Contains(strings.ToLower(want.ProjectTitle), strings.ToLower(query)) {
+			t.Errorf("%s: best match is %q", query, want.ProjectTitle)
+		}
+		// The unfiltered list's top project is a different one.
+		if first := m.projectsPosts[0]; first.ProjectNumber == want.ProjectNumber {
+			t.Fatalf("%s: pick a query that doesn't match the first project", query)
+		}
+
+		m = press(m, "enter")
+		if m.inProjectsList || m.selectedPost == nil || m.selectedPost.ProjectNumber != want.ProjectNumber {
+			t.Errorf("%s: opened %v, want #%d", query, m.selectedPost, want.ProjectNumber)
+		}
+		m = press(m, "backspace")
+		if !m.inProjectsList || !m.projectsList.IsFiltered() {
+			t.Errorf("%s: back from the project lost the filter", query)
+		}
+	}
+}
+
+// Sends a key and feeds back the list's filter results, which it works
+// out in a command.
+func filterKey(m Model, k string) Model {
+	next, cmd := m.Update(keyMsg(k))
+	m = next.(Model)
+	for _, msg := range run(cmd) {
+		if _, ok := msg.(list.FilterMatchesMsg); ok {
+			m = send(m, msg)
+		}
+	}
+	return m
+}
+
+func TestDigitsFilterWhileFiltering(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "p", "/")
+	m = typeText(m, "32")
+	if m.numBuf != "" || !m.inProjectsList {
+		t.Fatalf("digits in the filter picked a project: buffer %q, in list %v", m.numBuf, m.inProjectsList)
+	}
+	if v := m.projectsList.FilterValue(); v != "32" {
+		t.Errorf("filter %q, want 32", v)
+	}
+	m = press(m, "enter", "2")
+	if m.numBuf != "" || !m.inProjectsList {
+		t.Errorf("a digit on a filtered list picked by number: buffer %q, in list %v", m.numBuf, m.inProjectsList)
+	}
+}
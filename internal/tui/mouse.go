@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Lines moved per wheel notch.
+const wheelLines = 3
+
+// The mouse is only captured where there's something to scroll or click,
+// elsewhere it's released so text (like the contact details or a message)
+// can be selected as normal.
+func (m Model) wantsMouse() bool {
+	if m.State == StateProjects {
+		return true
+	}
+	return m.State != StateMessages && m.scrolling()
+}
+
+// Turns mouse reporting on or off when moving between states that differ.
+func mouseCmd(before, after Model) tea.Cmd {
+	switch was, want := before.wantsMouse(), after.wantsMouse(); {
+	case want && !was:
+		return tea.EnableMouseCellMotion
+	case was && !want:
+		return tea.DisableMouse
+	}
+	return nil
+}
+
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.tooSmall() || m.showHelp || m.searching {
+		return m, nil
+	}
+	inList := m.State == StateProjects && m.inProjectsList && !m.projectsList.SettingFilter()
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		if inList {
+			m.projectsList.CursorUp()
+		} else if m.scrolling() {
+			m.viewport.LineUp(wheelLines)
+		}
+	case msg.Button == tea.MouseButtonWheelDown:
+		if inList {
+			m.projectsList.CursorDown()
+		} else if m.scrolling() {
+			m.viewport.LineDown(wheelLines)
+		}
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress && msg.Y == m.height-1:
+		if k := m.footerKeyAt(msg.X); k != "" {
+			return m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress && inList:
+		if i := m.listItemAt(msg.Y - HeaderHeight); i >= 0 {
+			m.projectsList.Select(i)
+			if p, ok := m.projectsList.SelectedItem().(store.Project); ok {
+				m.openProject(&p)
+			}
+		}
+	}
+	return m, nil
+}
+
+// Index into the list's visible items of the project drawn at row y of
+// the list, or -1. Works off the rendered list rather than the delegate's
+// layout: walk up from the clicked row to the title of the entry it's
+// in, stopping at the blank line between entries.
+func (m Model) listItemAt(y int) int {
+	lines := strings.Split(m.projectsList.View(), "\n")
+	if y < 0 || y >= len(lines) {
+		return -1
+	}
+	items := m.projectsList.VisibleItems()
+	start, end := m.projectsList.Paginator.GetSliceBounds(len(items))
+	for row := y; row >= 0; row-- {
+		// Narrow windows truncate titles with an ellipsis.
+		line := strings.TrimSuffix(strings.TrimSpace(strings.TrimLeft(ansi.Strip(lines[row]), " │┃")), "…")
+		if line == "" {
+			return -1
+		}
+		for i := start; i < end; i++ {
+			if p, ok := items[i].(store.Project); ok && strings.HasPrefix(p.Title(), line) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// The key of the "k what it does" footer hint under column x, only for
+// single character keys so clicks never type anything odd.
+func (m Model) footerKeyAt(x int) string {
+	col := 0
+	for _, hint := range strings.Split(ansi.Strip(m.footerControls()), " • ") {
+		w := ansi.StringWidth(hint)
+		if x >= col && x < col+w {
+			k, _, ok := strings.Cut(strings.TrimSpace(hint), " ")
+			if !ok || len([]rune(k)) != 1 {
+				return ""
+			}
+			return k
+		}
+		col += w + ansi.StringWidth(" • ")
+	}
+	return ""
+}
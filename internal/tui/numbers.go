@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// How long a partly typed project number waits for more digits.
+const numTimeout = 800 * time.Millisecond
+
+type numTimeoutMsg struct{ seq int }
+
+func isDigit(s string) bool {
+	return len(s) == 1 && s[0] >= '0' && s[0] <= '9'
+}
+
+// Adds a digit to the pending number. Jumps straight away when no longer
+// project number could still match, so single digits stay instant when
+// there are fewer than 10 projects.
+func (m *Model) typeDigit(d string) tea.Cmd {
+	// A digit after the timeout starts over, even if the tick that
+	// would have cleared the buffer hasn't arrived yet.
+	if m.numBuf != "" && m.now().Sub(m.numAt) >= numTimeout {
+		m.numTimedOut()
+	}
+	m.numBuf += d
+	m.numSeq++
+	m.numAt = m.now()
+
+	exact, longer := false, false
+	for _, p := range m.projectsPosts {
+		n := strconv.Itoa(p.ProjectNumber)
+		if n == m.numBuf {
+			exact = true
+		} else if strings.HasPrefix(n, m.numBuf) {
+			longer = true
+		}
+	}
+
+	switch {
+	case exact && !longer:
+		m.jumpToNumber()
+		return nil
+	case !exact && !longer:
+		m.numBuf = ""
+		return nil
+	}
+	seq := m.numSeq
+	return tea.Tick(numTimeout, func(time.Time) tea.Msg { return numTimeoutMsg{seq: seq} })
+}
+
+// The wait for more digits is over, open what was typed if it names a
+// project on its own, e.g. "1" when there's also a 12.
+func (m *Model) numTimedOut() {
+	if m.State == StateProjects && m.inProjectsList {
+		m.jumpToNumber()
+	}
+	m.numBuf = ""
+}
+
+func (m *Model) jumpToNumber() {
+	num, err := strconv.Atoi(m.numBuf)
+	m.numBuf = ""
+	if i := store.FindByNumber(m.projectsPosts, num); err == nil && i >= 0 {
+		m.openProject(&m.projectsPosts[i])
+	}
+}
+
+func (m *Model) openProject(p *store.Project) {
+	m.selectedPost = p
+	m.inProjectsList = false
+	m.shownLink = 0
+	m.setViewportContent(hyperlinks(p.Detail(), m.linksEnabled()))
+	// Back where we left off if this project was open before, by line so
+	// a resize since doesn't move it.
+	if line, ok := m.scrollPos[p.ProjectNumber]; ok {
+		m.scrollToLine(line)
+	} else {
+		m.viewport.GotoTop()
+	}
+}
+
+// Remembers how far down the open project was scrolled, call before
+// anything that swaps the viewport away from it.
+func (m *Model) rememberScroll() {
+	if m.State == StateProjects && !m.inProjectsList && m.selectedPost != nil {
+		m.scrollPos[m.selectedPost.ProjectNumber] = m.topLine()
+	}
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// The projects list with projects numbered 1 to 12 and a clock that
+// only moves when the test says so.
+func numberedModel(t *testing.T) (Model, *time.Time) {
+	t.Helper()
+	m := newTestModel(t, 100, 30)
+	m = press(m, "p")
+	m.projectsPosts = nil
+	for n := 1; n <= 12; n++ {
+		m.projectsPosts = append(m.projectsPosts, store.Project{ProjectNumber: n, ProjectTitle: fmt.Sprintf("Project %d", n)})
+	}
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+// Presses a digit and returns the timeout it left waiting, if any.
+func digit(t *testing.T, m Model, d string) (Model, tea.Msg) {
+	t.Helper()
+	seq := m.numSeq
+	m = send(m, keyMsg(d))
+	if m.numBuf == "" {
+		return m, nil
+	}
+	if m.numSeq != seq+1 {
+		t.Fatalf("digit %s didn't bump numSeq", d)
+	}
+	return m, numTimeoutMsg{seq: m.numSeq}
+}
+
+func opened(m Model) int {
+	if m.inProjectsList || m.selectedPost == nil {
+		return -1
+	}
+	return m.selectedPost.ProjectNumber
+}
+
+func TestNumberTwoDigits(t *testing.T) {
+	m, now := numberedModel(t)
+	m, first := digit(t, m, "1")
+	if m.numBuf != "1" || opened(m) != -1 {
+		t.Fatalf("after 1: buffer %q, opened %d", m.numBuf, opened(m))
+	}
+	if !strings.Contains(m.View(), "#1_") {
+		t.Error("footer doesn't show the pending digit")
+	}
+
+	*now = now.Add(numTimeout / 2)
+	m, _ = digit(t, m, "2")
+	if got := opened(m); got != 12 {
+		t.Fatalf("1 then 2 opened %d, want 12", got)
+	}
+	if m.numBuf != "" {
+		t.Errorf("buffer %q left after the jump", m.numBuf)
+	}
+
+	// The first digit's timeout arriving late changes nothing.
+	m = send(m, first)
+	if got := opened(m); got != 12 {
+		t.Errorf("stale timeout moved to %d", got)
+	}
+}
+
+func TestNumberTimeoutOpensPrefix(t *testing.T) {
+	m, now := numberedModel(t)
+	m, timeout := digit(t, m, "1")
+	*now = now.Add(numTimeout)
+	m = send(m, timeout)
+	if got := opened(m); got != 1 {
+		t.Errorf("1 then the timeout opened %d, want 1", got)
+	}
+}
+
+func TestNumberSlowDigitStartsOver(t *testing.T) {
+	m, now := numberedModel(t)
+	m, _ = digit(t, m, "1")
+	// Past the timeout, but its tick hasn't been delivered yet.
+	*now = now.Add(numTimeout + time.Millisecond)
+	m = send(m, keyMsg("2"))
+	if got := opened(m); got != 2 {
+		t.Errorf("slow 1 then 2 opened %d, want 2", got)
+	}
+}
+
+func TestNumberOutOfRange(t *testing.T) {
+	m, now := numberedModel(t)
+	m = press(m, "down")
+	before := m.projectsList.Index()
+
+	m = send(m, keyMsg("0"))
+	m, _ = digit(t, m, "1")
+	m = send(m, keyMsg("9"))
+	m, timeout := digit(t, m, "1")
+	*now = now.Add(numTimeout)
+	m.projectsPosts = m.projectsPosts[1:] // 1 went away meanwhile
+	m = send(m, timeout)
+
+	if opened(m) != -1 || m.numBuf != "" {
+		t.Errorf("opened %d, buffer %q", opened(m), m.numBuf)
+	}
+	if m.projectsList.Index() != before {
+		t.Errorf("cursor moved from %d to %d", before, m.projectsList.Index())
+	}
+}
+
+func TestNumberTimeoutAfterLeaving(t *testing.T) {
+	m, now := numberedModel(t)
+	m, timeout := digit(t, m, "1")
+	m = press(m, "o")
+	*now = now.Add(numTimeout)
+	m = send(m, timeout)
+	if m.State != StateHome || m.selectedPost != nil {
+		t.Errorf("timeout after leaving: state %v, opened %v", m.State, m.selectedPost)
+	}
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/template"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Built in copy of each page (home.md, ...), used for any LoadPages
+// doesn't find. main sets it to the embedded content directory.
+var DefaultPages fs.FS
+
+var pageNames = []string{"home", "blog", "contact"}
+
+var (
+	pagesMu     sync.RWMutex
+	pages       = map[string]*template.Template{}
+	pageSources = map[string]string{} // as last loaded, to log which changed
+)
+
+// What pages can use, e.g. {{.Uptime}}, filled in each time one is shown.
+type pageData struct {
+	Uptime       string
+	VisitorCount int
+}
+
+// Reads home.md, blog.md and contact.md from dir, missing ones keep the
+// built in copy. Nothing changes if any of them fails to parse, so a
+// reload with a broken page keeps the last good set.
+func LoadPages(dir string) error {
+	parsed := map[string]*template.Template{}
+	sources := map[string]string{}
+	for _, name := range pageNames {
+		path := filepath.Join(dir, name+".md")
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && DefaultPages != nil:
+			if data, err = fs.ReadFile(DefaultPages, name+".md"); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			log.Info("Loaded page", "path", path)
+		}
+		t, err := template.New(name).Parse(string(data))
+		if err != nil {
+			return err
+		}
+		parsed[name] = t
+		sources[name] = string(data)
+	}
+	pagesMu.Lock()
+	for name, src := range sources {
+		if prev, ok := pageSources[name]; ok && prev != src {
+			log.Info("Page changed", "page", name)
+		}
+	}
+	pages, pageSources = parsed, sources
+	pagesMu.Unlock()
+	return nil
+}
+
+// The named page with its variables filled in.
+func page(name string) string {
+	pagesMu.RLock()
+	t := pages[name]
+	pagesMu.RUnlock()
+	if t == nil {
+		return ""
+	}
+
+	data := pageData{
+		Uptime:       humanDuration(store.Uptime()),
+		VisitorCount: store.AnalyticsSnapshot().UniqueVisitors,
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		log.Error("Could not render page", "page", name, "error", err)
+	}
+	return b.String()
+}
+
+func homeBody() string {
+	return "\n" + page("home")
+}
+
+func blogContent() string {
+	return page("blog")
+}
+
+func contactContent() string {
+	return "\n" + page("contact")
+}
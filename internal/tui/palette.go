@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+func (m *Model) startCommand() tea.Cmd {
+	m.commanding = true
+	m.commandInput.SetValue("")
+	m.commandInput.Focus()
+	return textinput.Blink
+}
+
+// Keys while typing a command, enter runs it and esc gives up.
+func (m Model) updateCommand(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.commanding = false
+		m.commandInput.Blur()
+	case "enter":
+		m.commanding = false
+		m.commandInput.Blur()
+		return m.runCommand(m.commandInput.Value())
+	default:
+		var cmd tea.Cmd
+		m.commandInput, cmd = m.commandInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Runs one ':' command, each also answers to its section's key. A
+// mistake is left in commandErr for the footer.
+func (m Model) runCommand(line string) (tea.Model, tea.Cmd) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), ":"))
+	if len(fields) == 0 {
+		return m, nil
+	}
+	name, arg := strings.ToLower(fields[0]), strings.Join(fields[1:], " ")
+	takesArg := name == "projects" || name == "p" || name == "theme" || name == "t"
+	if arg != "" && !takesArg {
+		m.commandErr = fmt.Sprintf(":%s doesn't take an argument", name)
+		return m, nil
+	}
+
+	m.rememberScroll()
+	switch name {
+	case "home", "o":
+		m.goHome()
+	case "projects", "p":
+		if arg == "" {
+			m.openSection(StateProjects)
+			m.selectedPost = nil
+			break
+		}
+		n, err := strconv.Atoi(arg)
+		i := store.FindByNumber(m.projectsPosts, n)
+		if err != nil || i < 0 {
+			m.commandErr = "No project " + arg
+			break
+		}
+		m.State = StateProjects
+		m.openProject(&m.projectsPosts[i])
+	case "blog", "b":
+		m.openSection(StateBlog)
+	case "contact", "c":
+		m.openSection(StateContact)
+	case "messages", "message", "m":
+		m.openMessages()
+		return m, textarea.Blink
+	case "stats", "s":
+		m.openSection(StateStats)
+	case "status":
+		m.openSection(StateStatus)
+	case "guestbook", "w":
+		if !GuestbookEnabled {
+			m.commandErr = "Not a command: " + name
+			break
+		}
+		m.openSection(StateGuestbook)
+	case "inbox", "i":
+		if !m.admin {
+			m.commandErr = "Not a command: " + name
+			break
+		}
+		m.openInbox()
+	case "theme", "t":
+		if arg == "" {
+			m.nextTheme()
+			break
+		}
+		for i, t := range Themes {
+			if strings.EqualFold(t.Name, arg) {
+				m.applyTheme(i)
+				m.refreshHome()
+				return m, nil
+			}
+		}
+		m.commandErr = "No theme " + arg
+	case "help", "h":
+		m.showHelp = true
+	case "quit", "q":
+		return m, tea.Quit
+	default:
+		m.commandErr = "Not a command: " + name
+	}
+	return m, nil
+}
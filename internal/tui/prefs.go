@@ -0,0 +1,32 @@
+package tui
+
+import "github.com/will-x86/ssh-will-x86/internal/store"
+
+// What's remembered for visitors with a key, saved whenever it changes.
+func (m Model) prefs() store.Prefs {
+	return store.Prefs{
+		Name:    m.chosenName,
+		Theme:   Themes[m.theme].Name,
+		Section: m.State.String(),
+	}
+}
+
+// Puts a returning visitor back how they left it. Only the plain
+// sections are returned to, anywhere else starts at home.
+func (m *Model) restorePrefs(p store.Prefs) {
+	if p.Name != "" {
+		m.username = p.Name
+		m.chosenName = p.Name
+	}
+	for i, t := range Themes {
+		if t.Name == p.Theme {
+			m.applyTheme(i)
+			m.refreshHome()
+		}
+	}
+	for _, s := range []State{StateProjects, StateBlog, StateContact, StateStats, StateGuestbook} {
+		if s.String() == p.Section {
+			m.openSection(s)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// How often the "people browsing" count in the header is refreshed.
+const presenceEvery = 5 * time.Second
+
+type presenceMsg struct{ active int64 }
+
+func presenceTick() tea.Cmd {
+	return tea.Tick(presenceEvery, func(time.Time) tea.Msg { return presenceNow() })
+}
+
+func presenceNow() tea.Msg {
+	return presenceMsg{active: store.ActiveSessions()}
+}
+
+func (m Model) presenceText() string {
+	if m.presence <= 1 {
+		return "just you here"
+	}
+	return fmt.Sprintf("%d people browsing", m.presence)
+}
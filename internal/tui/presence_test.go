@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Opens n sessions on the server's count, closing any still open when
+// the test ends.
+func openSessions(t *testing.T, n int) (close func()) {
+	t.Helper()
+	open := n
+	for range n {
+		store.SessionStarted()
+	}
+	t.Cleanup(func() {
+		for ; open > 0; open-- {
+			store.SessionEnded()
+		}
+	})
+	return func() {
+		if open > 0 {
+			store.SessionEnded()
+			open--
+		}
+	}
+}
+
+func TestPresenceFollowsSessions(t *testing.T) {
+	if n := store.ActiveSessions(); n != 0 {
+		t.Fatalf("%d sessions open before the test", n)
+	}
+	// This visitor's own session.
+	openSessions(t, 1)
+	m := newTestModel(t, 120, 30)
+	if !strings.Contains(m.View(), "just you here") {
+		t.Fatal("alone, the header doesn't say so")
+	}
+
+	closeOne := openSessions(t, 3)
+	m = send(m, presenceNow())
+	if !strings.Contains(m.View(), "4 people browsing") {
+		t.Errorf("with 3 others: header %q", firstLine(m.View()))
+	}
+
+	closeOne()
+	closeOne()
+	m = send(m, presenceNow())
+	if !strings.Contains(m.View(), "2 people browsing") {
+		t.Errorf("after 2 left: header %q", firstLine(m.View()))
+	}
+
+	closeOne()
+	m = send(m, presenceNow())
+	if !strings.Contains(m.View(), "just you here") {
+		t.Errorf("after everyone left: header %q", firstLine(m.View()))
+	}
+}
+
+// The count is the first thing a narrow header drops, it mustn't push
+// the tabs off the line.
+func TestPresenceNarrowHeader(t *testing.T) {
+	openSessions(t, 12)
+	m := newTestModel(t, 120, 30)
+	m = send(m, presenceNow())
+	if !strings.Contains(firstLine(m.View()), "12 people browsing") {
+		t.Fatalf("wide header %q", firstLine(m.View()))
+	}
+	for _, w := range []int{80, 60, 40} {
+		m = send(m, tea.WindowSizeMsg{Width: w, Height: 30})
+		fitsWindow(t, "header", firstLine(m.View()), w)
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Anything shown through the viewport can be searched: the pages,
+// stats, status, the guestbook, a project's write up and an open inbox
+// message. The lists have their own filtering.
+func (m Model) canSearch() bool {
+	switch m.State {
+	case StateProjects:
+		return !m.inProjectsList && m.selectedPost != nil
+	case StateInbox:
+		return !m.inInboxList && m.openMessage != nil
+	case StateHome, StateBlog, StateContact, StateStats, StateStatus, StateGuestbook:
+		return true
+	}
+	return false
+}
+
+// Sets what the viewport shows, kept unwrapped so it can be re-wrapped
+// on resize and re-highlighted when the query changes. New content
+// starts without a search.
+func (m *Model) setViewportContent(s string) {
+	m.viewportRaw = s
+	m.query = ""
+	m.refreshViewport()
+}
+
+func (m *Model) refreshViewport() {
+	wrapped := wrapLines(m.viewportRaw, m.viewport.Width-m.viewport.Style.GetHorizontalFrameSize())
+	m.matches = matchLines(wrapped, m.query)
+	m.viewport.SetContent(highlightMatches(wrapped, m.query, m.MatchStyle))
+}
+
+// The viewport truncates long lines, so wrap them first. Search works on
+// the wrapped lines so a match's index is its viewport offset. Words
+// wider than the viewport are broken, widths are display cells so wide
+// runes (CJK, emoji) count double.
+func wrapLines(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	return carryLinks(ansi.Wrap(s, width, ""))
+}
+
+var osc8 = regexp.MustCompile(`\x1b\]8;[^;\x07\x1b]*;([^\x07\x1b]*)(?:\x07|\x1b\\)`)
+
+// A link wrapped over several lines is closed at the end of each and
+// reopened at the start of the next, otherwise only its first line is
+// clickable.
+func carryLinks(s string) string {
+	if !strings.Contains(s, "\x1b]8;") {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	open := ""
+	for i, l := range lines {
+		l = open + l
+		if links := osc8.FindAllStringSubmatch(l, -1); len(links) > 0 {
+			if last := links[len(links)-1]; last[1] != "" {
+				open = last[0]
+			} else {
+				open = ""
+			}
+		}
+		if open != "" {
+			l += "\x1b]8;;\x1b\\"
+		}
+		lines[i] = l
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Indexes of the lines containing q, ignoring case and escape sequences.
+func matchLines(s, q string) []int {
+	if q == "" {
+		return nil
+	}
+	q = strings.ToLower(q)
+	var lines []int
+	for i, line := range strings.Split(s, "\n") {
+		if strings.Contains(strings.ToLower(ansi.Strip(line)), q) {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
+
+func highlightMatches(s, q string, style lipgloss.Style) string {
+	if q == "" {
+		return s
+	}
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(q))
+	return mapText(s, func(text string) string {
+		return re.ReplaceAllStringFunc(text, func(match string) string { return style.Render(match) })
+	})
+}
+
+func (m *Model) startSearch() tea.Cmd {
+	m.searching = true
+	m.searchInput.SetValue("")
+	m.searchInput.Focus()
+	return textinput.Blink
+}
+
+// Keys while typing a query, enter searches and esc gives up.
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.searching = false
+		m.searchInput.Blur()
+		m.clearSearch()
+	case "enter":
+		m.searching = false
+		m.searchInput.Blur()
+		m.query = m.searchInput.Value()
+		m.refreshViewport()
+		m.jumpToMatch(0)
+	default:
+		var cmd tea.Cmd
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *Model) clearSearch() {
+	if m.query == "" {
+		return
+	}
+	m.query = ""
+	m.refreshViewport()
+}
+
+// Scrolls to the i'th match, wrapping around at either end.
+func (m *Model) jumpToMatch(i int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.matchIdx = (i%len(m.matches) + len(m.matches)) % len(m.matches)
+	m.viewport.SetYOffset(m.matches[m.matchIdx])
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestCanSearchViewportStates(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	for _, key := range []string{"o", "b", "c", "s", "U"} {
+		m = press(m, key)
+		if !m.canSearch() {
+			t.Errorf("can't search in %v", m.State)
+		}
+		m = press(m, "/")
+		if !m.searching {
+			t.Errorf("/ didn't start a search in %v", m.State)
+		}
+		m = press(m, "esc")
+	}
+
+	m = press(m, "p")
+	if m.canSearch() {
+		t.Error("can search the projects list, it has its own filter")
+	}
+	m = press(m, "m")
+	if m.canSearch() {
+		t.Error("can search while writing a message")
+	}
+}
+
+func TestSearchBlog(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "b")
+	page := strings.ToLower(ansi.Strip(m.viewportRaw))
+	word := strings.Fields(page)[len(strings.Fields(page))/2]
+
+	m = press(m, "/")
+	m = typeText(m, word)
+	m = press(m, "enter")
+	if len(m.matches) == 0 {
+		t.Fatalf("no matches for %q on the blog page", word)
+	}
+	if got := m.footerStatus(); !strings.Contains(got, "match 1 of") {
+		t.Errorf("footer = %q, want the match count", got)
+	}
+}
+
+// Matches are indexes into the wrapped lines, so jumping to one puts the
+// line holding it at the top of the viewport.
+func TestMatchesAreWrappedLines(t *testing.T) {
+	m := newTestModel(t, 60, 20)
+	m = press(m, "s")
+
+	var b strings.Builder
+	for i := range 40 {
+		if i == 5 {
+			// One long line, the needle ends up a few wrapped lines down.
+			b.WriteString(strings.Repeat("filler words here ", 12) + "needle\n")
+			continue
+		}
+		b.WriteString("line\n")
+	}
+	b.WriteString("another needle at the end")
+	m.setViewportContent(b.String())
+
+	m = press(m, "/")
+	m = typeText(m, "NEEDLE")
+	m = press(m, "enter")
+
+	wrapped := strings.Split(wrapLines(m.viewportRaw, m.viewport.Width-m.viewport.Style.GetHorizontalFrameSize()), "\n")
+	if len(m.matches) != 2 {
+		t.Fatalf("matches = %v, want 2", m.matches)
+	}
+	for _, i := range m.matches {
+		if !strings.Contains(wrapped[i], "needle") {
+			t.Errorf("match at wrapped line %d is %q", i, wrapped[i])
+		}
+	}
+	if m.matches[0] <= 5 {
+		t.Errorf("first match at line %d, want it past the unwrapped line 5", m.matches[0])
+	}
+	if m.viewport.YOffset != m.matches[0] {
+		t.Errorf("viewport at %d, want the first match at %d", m.viewport.YOffset, m.matches[0])
+	}
+
+	m = press(m, "n", "n")
+	if m.matchIdx != 0 || m.viewport.YOffset != m.matches[0] {
+		t.Errorf("n twice: match %d at %d, want it to wrap to the first", m.matchIdx, m.viewport.YOffset)
+	}
+	m = press(m, "N")
+	if m.matchIdx != 1 {
+		t.Errorf("N from the first match: %d, want the last", m.matchIdx)
+	}
+}
+
+func TestWrapLinesWidths(t *testing.T) {
+	for _, s := range []string{
+		strings.Repeat("日本語 ", 20),
+		strings.Repeat("👋🏽👩‍👩‍👧 word ", 10),
+		"a" + strings.Repeat("漢", 30),
+		strings.Repeat("x", 45),
+	} {
+		wrapped := wrapLines(s, 20)
+		for _, line := range strings.Split(wrapped, "\n") {
+			if w := ansi.StringWidth(line); w > 20 {
+				t.Errorf("%d cell line %q from %q", w, line, s)
+			}
+		}
+		if strings.ReplaceAll(strings.ReplaceAll(wrapped, "\n", ""), " ", "") != strings.ReplaceAll(s, " ", "") {
+			t.Errorf("wrapping %q lost text: %q", s, wrapped)
+		}
+	}
+}
+
+// Styling and links survive wrapping, every line's escapes are whole
+// and a link split over lines is clickable on each.
+func TestWrapLinesANSI(t *testing.T) {
+	styled := "\x1b[1m" + strings.Repeat("粗体 bold ", 6) + "\x1b[0m"
+	for _, line := range strings.Split(wrapLines(styled, 15), "\n") {
+		if w := ansi.StringWidth(line); w > 15 {
+			t.Errorf("%d cell styled line %q", w, line)
+		}
+		if strings.Count(line, "\x1b") != strings.Count(line, "\x1b[") {
+			t.Errorf("split escape in %q", line)
+		}
+	}
+	if got := strings.ReplaceAll(ansi.Strip(wrapLines(styled, 15)), "\n", ""); strings.ReplaceAll(got, " ", "") != strings.ReplaceAll(ansi.Strip(styled), " ", "") {
+		t.Errorf("wrapping styled text lost some of it: %q", got)
+	}
+
+	url := "https://example.com/" + strings.Repeat("very-long-path/", 5)
+	linked := ansi.SetHyperlink(url) + url + ansi.ResetHyperlink()
+	lines := strings.Split(wrapLines("see "+linked, 20), "\n")[1:] // "see" fits alone
+	if len(lines) < 3 {
+		t.Fatalf("a %d cell link wrapped to %d lines", len(url), len(lines))
+	}
+	var text strings.Builder
+	for i, line := range lines {
+		if w := ansi.StringWidth(line); w > 20 {
+			t.Errorf("%d cell linked line %q", w, line)
+		}
+		if !strings.HasPrefix(line, ansi.SetHyperlink(url)) {
+			t.Errorf("line %d doesn't open the link: %q", i, line)
+		}
+		if !strings.HasSuffix(line, "\x1b]8;;\x07") && !strings.HasSuffix(line, "\x1b]8;;\x1b\\") {
+			t.Errorf("line %d leaves the link open: %q", i, line)
+		}
+		text.WriteString(ansi.Strip(line))
+	}
+	if got := text.String(); got != url {
+		t.Errorf("link text after wrapping %q, want %q", got, url)
+	}
+}
+
+// A page short enough to be centered instead of scrolled still shows
+// what the footer says matched.
+func TestSearchHighlightsPageThatFits(t *testing.T) {
+	m := modelWithProfile(t, termenv.ANSI256)
+	m = press(m, "c")
+	if m.scrolling() {
+		t.Fatal("contact page scrolls, want one that fits")
+	}
+	m = press(typeText(press(m, "/"), "github"), "enter")
+	if len(m.matches) == 0 {
+		t.Fatal("no matches for github on the contact page")
+	}
+	view := m.View()
+	if !strings.Contains(view, "\x1b[7mGithub") {
+		t.Errorf("match not highlighted on a page that fits:\n%q", view)
+	}
+	if !strings.Contains(ansi.Strip(view), "match 1 of") {
+		t.Errorf("footer doesn't report the match:\n%s", ansi.Strip(view))
+	}
+
+	m = press(m, "esc")
+	if strings.Contains(m.View(), "\x1b[7m") {
+		t.Error("highlight left after clearing the search")
+	}
+}
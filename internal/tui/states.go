@@ -0,0 +1,43 @@
+package tui
+
+// current view/page
+type State int
+
+const (
+	StateDefault   State = iota // landing / welcome screen
+	StateHome                   // home / bio
+	StateProjects               // projects list + detail
+	StateBlog                   // blog pointer
+	StateContact                // contact info
+	StateMessages               // leave-a-message form
+	StateInbox                  // owner only, reading sent messages
+	StateStats                  // visitor counts
+	StateGuestbook              // approved messages, read only
+	StateStatus                 // uptime, memory and the server's clock
+)
+
+// Section name as counted by store.RecordView.
+func (s State) String() string {
+	switch s {
+	case StateHome:
+		return "home"
+	case StateProjects:
+		return "projects"
+	case StateBlog:
+		return "blog"
+	case StateContact:
+		return "contact"
+	case StateMessages:
+		return "messages"
+	case StateInbox:
+		return "inbox"
+	case StateStats:
+		return "stats"
+	case StateGuestbook:
+		return "guestbook"
+	case StateStatus:
+		return "status"
+	default:
+		return "default"
+	}
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Sections in the order they're charted.
+var chartedSections = []State{StateHome, StateProjects, StateBlog, StateContact, StateMessages}
+
+// Totals plus a bar per section, scaled to the widest bar that fits.
+func (m Model) statsContent() string {
+	a := store.AnalyticsSnapshot()
+	v := store.Visitors()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sessions: %d (%d online now)\n", a.Sessions, v.ActiveSessions)
+	fmt.Fprintf(&b, "Unique visitors: %d\n", a.UniqueVisitors)
+	fmt.Fprintf(&b, "Messages since restart: %d\n\n", v.TotalMessages)
+
+	var most int64 = 1
+	for _, s := range chartedSections {
+		most = max(most, a.Views[s.String()])
+	}
+	labelWidth := 0
+	for _, s := range chartedSections {
+		labelWidth = max(labelWidth, len(s.String()))
+	}
+	barWidth := max(min(m.width-labelWidth-16, 50), 1)
+	bar := m.TxtStyle
+
+	names := make([]string, 0, len(chartedSections))
+	for _, s := range chartedSections {
+		names = append(names, s.String())
+	}
+	sort.SliceStable(names, func(i, j int) bool { return a.Views[names[i]] > a.Views[names[j]] })
+	for _, name := range names {
+		n := a.Views[name]
+		blocks := int(n * int64(barWidth) / most)
+		fmt.Fprintf(&b, "%-*s %s %d\n", labelWidth, name, bar.Render(strings.Repeat("█", blocks)), n)
+	}
+	return lipgloss.NewStyle().Align(lipgloss.Left).Render(b.String())
+}
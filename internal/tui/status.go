@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// How often the status page's clock moves, it only ticks while the page
+// is open.
+const statusEvery = time.Second
+
+type statusTickMsg struct{ seq int }
+
+func statusTick(seq int) tea.Cmd {
+	return tea.Tick(statusEvery, func(time.Time) tea.Msg { return statusTickMsg{seq: seq} })
+}
+
+// Starts the clock when the status page has just been opened, bumping
+// statusSeq so a tick left over from an earlier visit stops.
+func statusCmd(prev Model, next *Model) tea.Cmd {
+	if next.State != StateStatus || prev.State == StateStatus {
+		return nil
+	}
+	next.statusSeq++
+	return statusTick(next.statusSeq)
+}
+
+// Redraws the page and waits for the next second, or lets the clock stop
+// once the page has been left.
+func (m *Model) statusTicked(msg statusTickMsg) tea.Cmd {
+	if msg.seq != m.statusSeq || m.State != StateStatus {
+		return nil
+	}
+	m.viewportRaw = m.statusContent()
+	m.refreshViewport()
+	return statusTick(m.statusSeq)
+}
+
+// The server this is running on, from the same counters as the stats
+// page and the header.
+func (m Model) statusContent() string {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	v := store.Visitors()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Local time: %s\n", m.now().Format("Mon 2 Jan 15:04:05 MST"))
+	fmt.Fprintf(&b, "Uptime: %s\n", humanDuration(store.Uptime()))
+	fmt.Fprintf(&b, "Version: %s (%s)\n\n", store.Version, runtime.Version())
+	fmt.Fprintf(&b, "Sessions served: %d (%d online now)\n", v.TotalConnections, v.ActiveSessions)
+	fmt.Fprintf(&b, "Messages waiting to print: %d\n\n", store.QueueStats().Queued)
+	fmt.Fprintf(&b, "Memory: %s in use, %s from the OS\n", humanBytes(mem.HeapAlloc), humanBytes(mem.Sys))
+	fmt.Fprintf(&b, "Goroutines: %d\n", runtime.NumGoroutine())
+	return lipgloss.NewStyle().Align(lipgloss.Left).Render(b.String())
+}
+
+// The two biggest units of d, e.g. "3d 4h" or "12m 5s".
+func humanDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	units := []struct {
+		size time.Duration
+		name string
+	}{{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}}
+	for i, u := range units {
+		if d < u.size && i < len(units)-1 {
+			continue
+		}
+		s := fmt.Sprintf("%d%s", d/u.size, u.name)
+		if i < len(units)-1 {
+			if rest := d % u.size / units[i+1].size; rest > 0 {
+				s += fmt.Sprintf(" %d%s", rest, units[i+1].name)
+			}
+		}
+		return s
+	}
+	return "0s"
+}
+
+// n in B, KiB, MiB or GiB.
+func humanBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	size, exp := float64(n)/unit, 0
+	for size >= unit && exp < 2 {
+		size /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", size, "KMG"[exp])
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Sections with a tab in the header, in the order tab and the arrows
+// move through them.
+var tabs = []struct {
+	state State
+	label string
+}{
+	{StateHome, "Home"},
+	{StateProjects, "Projects"},
+	{StateBlog, "Blog"},
+	{StateContact, "Contact"},
+	{StateMessages, "Messages"},
+}
+
+// Moves delta tabs along, wrapping at either end. From a section without
+// a tab (stats, the inbox) the first step lands on Home or Messages.
+func (m *Model) switchTab(delta int) {
+	cur := -1
+	for i, t := range tabs {
+		if t.state == m.State {
+			cur = i
+		}
+	}
+	next := 0
+	switch {
+	case cur >= 0:
+		next = ((cur+delta)%len(tabs) + len(tabs)) % len(tabs)
+	case delta < 0:
+		next = len(tabs) - 1
+	}
+
+	m.rememberScroll()
+	m.numBuf = ""
+	switch s := tabs[next].state; s {
+	case StateMessages:
+		m.openMessages()
+	case StateProjects:
+		m.openSection(s)
+		m.selectedPost = nil
+	default:
+		m.openSection(s)
+	}
+}
+
+func (m *Model) openMessages() {
+	m.State = StateMessages
+	m.messageSent = false
+	m.editingName = false
+	m.confirming = false
+	m.tooLong = false
+	m.sendErr = nil
+	m.vimNormal = false
+	m.messageInput.Focus()
+}
+
+// Where the visitor is, e.g. "willx86.com › projects › <title>", shown
+// in place of the bare site name.
+func (m Model) breadcrumb() string {
+	parts := []string{"willx86.com"}
+	switch {
+	case m.State == StateHome || m.State == StateDefault:
+	case m.State == StateProjects && !m.inProjectsList && m.selectedPost != nil:
+		parts = append(parts, "projects", m.selectedPost.ProjectTitle)
+	case m.State == StateInbox && m.inQuarantine:
+		parts = append(parts, "inbox", "quarantine")
+	default:
+		parts = append(parts, m.State.String())
+	}
+	return strings.Join(parts, " › ")
+}
+
+// Breadcrumb and tabs on the left, who else is here on the right. Narrow
+// windows get the tabs' initials, then lose the presence, then the end
+// of the breadcrumb.
+func (m Model) header() string {
+	ascii := m.profile == "Ascii"
+	crumb := m.breadcrumb()
+	presence := m.presenceText() + "  "
+
+	labels := func(short bool) ([]string, int) {
+		out := make([]string, len(tabs))
+		w := 0
+		for i, t := range tabs {
+			out[i] = t.label
+			if short {
+				out[i] = t.label[:1]
+			}
+			// No styling gets through to Ascii terminals, brackets stand in.
+			if ascii && t.state == m.State {
+				out[i] = "[" + out[i] + "]"
+			}
+			w += len(out[i])
+			if i > 0 {
+				w += len(" | ")
+			}
+		}
+		return out, w
+	}
+	const pad = 2 + 3 // before the breadcrumb and between it and the tabs
+	fixed := pad + ansi.StringWidth(crumb)
+	ls, w := labels(false)
+	if fixed+w+len(presence) > m.width {
+		ls, w = labels(true)
+	}
+	if fixed+w+len(presence) > m.width {
+		presence = ""
+	}
+	if fixed+w > m.width {
+		crumb = ansi.Truncate(crumb, max(m.width-pad-w, 1), "…")
+		fixed = pad + ansi.StringWidth(crumb)
+	}
+	gap := max(m.width-fixed-w-len(presence), 0)
+
+	if ascii {
+		// Drawn with text, like the rest of the bar.
+		line := "= " + crumb + "   " + strings.Join(ls, " | ") + " " + strings.Repeat("=", max(gap-2, 0)) + " " + presence
+		return ansi.Truncate(line, m.width, "")
+	}
+	bar := m.HeaderStyle.UnsetPaddingLeft()
+	var b strings.Builder
+	b.WriteString(bar.Render("  " + crumb + "   "))
+	for i, l := range ls {
+		if i > 0 {
+			b.WriteString(bar.Render(" | "))
+		}
+		if tabs[i].state == m.State {
+			b.WriteString(m.TabStyle.Render(l))
+		} else {
+			b.WriteString(bar.Render(l))
+		}
+	}
+	b.WriteString(bar.Render(strings.Repeat(" ", gap) + presence))
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+var tabOrder = []State{StateHome, StateProjects, StateBlog, StateContact, StateMessages}
+
+func TestTabCycle(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	// Twice round, so the wrap from Messages back to Home is crossed
+	// in the middle of a run too.
+	for i := 1; i <= 2*len(tabOrder); i++ {
+		m = press(m, "tab")
+		if want := tabOrder[i%len(tabOrder)]; m.State != want {
+			t.Fatalf("tab %d: %v, want %v", i, m.State, want)
+		}
+	}
+	for i := 1; i <= 2*len(tabOrder); i++ {
+		m = press(m, "shift+tab")
+		want := tabOrder[((-i)%len(tabOrder)+len(tabOrder))%len(tabOrder)]
+		if m.State != want {
+			t.Fatalf("shift+tab %d: %v, want %v", i, m.State, want)
+		}
+	}
+}
+
+func TestTabWrapsAtEnds(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	if m = press(m, "shift+tab"); m.State != StateMessages {
+		t.Errorf("shift+tab from Home: %v, want messages", m.State)
+	}
+	if !m.messageInput.Focused() {
+		t.Error("landing on Messages didn't focus the editor")
+	}
+	if m = press(m, "tab"); m.State != StateHome {
+		t.Errorf("tab from Messages: %v, want home", m.State)
+	}
+	if m.messageInput.Focused() {
+		t.Error("editor still focused after tabbing away")
+	}
+}
+
+// Arrows move between tabs too, except in lists where they page.
+func TestArrowsSwitchTabs(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	if m = press(m, "right"); m.State != StateProjects {
+		t.Fatalf("right from Home: %v", m.State)
+	}
+	if m = press(m, "right"); m.State != StateProjects {
+		t.Errorf("right in the projects list left it for %v", m.State)
+	}
+	m = press(m, "tab")
+	if m = press(m, "left"); m.State != StateProjects {
+		t.Errorf("left from Blog: %v, want projects", m.State)
+	}
+}
+
+// Tabbing out of Messages keeps the draft, out of a project forgets
+// which one was open.
+func TestTabKeepsDraftAndClosesProject(t *testing.T) {
+	m := newTestModel(t, 100, 30)
+	m = press(m, "m")
+	m = typeText(m, "half written")
+	m = press(m, "tab", "shift+tab")
+	if m.State != StateMessages || m.messageInput.Value() != "half written" {
+		t.Fatalf("back in Messages: %v with %q", m.State, m.messageInput.Value())
+	}
+
+	m = press(m, "tab", "tab", "enter")
+	if m.State != StateProjects || m.selectedPost == nil {
+		t.Fatal("couldn't open a project")
+	}
+	m = press(m, "tab", "shift+tab")
+	if m.selectedPost != nil || !m.inProjectsList {
+		t.Error("tabbing back to Projects reopened the project")
+	}
+}
+
+// Sections without a tab step onto the first or last one.
+func TestTabFromUntabbedSection(t *testing.T) {
+	for _, tt := range []struct {
+		key  string
+		want State
+	}{{"tab", StateHome}, {"shift+tab", StateMessages}} {
+		m := newTestModel(t, 100, 30)
+		m = press(m, "s")
+		if m.State != StateStats {
+			t.Fatalf("s: %v, want stats", m.State)
+		}
+		if m = press(m, tt.key); m.State != tt.want {
+			t.Errorf("%s from stats: %v, want %v", tt.key, m.State, tt.want)
+		}
+	}
+}
+
+// The breadcrumb follows the tab.
+func TestTabBreadcrumb(t *testing.T) {
+	m := newTestModel(t, 120, 30)
+	for _, s := range tabOrder[1:] {
+		m = press(m, "tab")
+		if want := "willx86.com › " + s.String(); !strings.Contains(m.breadcrumb(), want) {
+			t.Errorf("%v: breadcrumb %q, want %q", s, m.breadcrumb(), want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Colors for one look of the site, switched per session with 't'.
+type Theme struct {
+	Name         string
+	Header       lipgloss.TerminalColor // header bar background
+	HeaderText   lipgloss.TerminalColor
+	Text         lipgloss.TerminalColor
+	Footer       lipgloss.TerminalColor
+	Border       lipgloss.TerminalColor
+	Selected     lipgloss.TerminalColor // list selection title and bar
+	SelectedDesc lipgloss.TerminalColor
+	Dimmed       lipgloss.TerminalColor // list descriptions and filtered out entries
+}
+
+var Themes = []Theme{
+	{
+		Name:         "default",
+		Header:       lipgloss.Color("62"),
+		HeaderText:   lipgloss.NoColor{},
+		Text:         lipgloss.AdaptiveColor{Light: "2", Dark: "10"},
+		Footer:       lipgloss.AdaptiveColor{Light: "0", Dark: "15"},
+		Border:       lipgloss.NoColor{},
+		Selected:     lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"},
+		SelectedDesc: lipgloss.AdaptiveColor{Light: "#F793FF", Dark: "#AD58B4"},
+		Dimmed:       lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"},
+	},
+	{
+		Name:         "gruvbox",
+		Header:       lipgloss.Color("#458588"),
+		HeaderText:   lipgloss.Color("#ebdbb2"),
+		Text:         lipgloss.Color("#b8bb26"),
+		Footer:       lipgloss.Color("#a89984"),
+		Border:       lipgloss.Color("#665c54"),
+		Selected:     lipgloss.Color("#fabd2f"),
+		SelectedDesc: lipgloss.Color("#d79921"),
+		Dimmed:       lipgloss.Color("#928374"),
+	},
+	{
+		Name:         "nord",
+		Header:       lipgloss.Color("#5E81AC"),
+		HeaderText:   lipgloss.Color("#ECEFF4"),
+		Text:         lipgloss.Color("#A3BE8C"),
+		Footer:       lipgloss.Color("#D8DEE9"),
+		Border:       lipgloss.Color("#4C566A"),
+		Selected:     lipgloss.Color("#88C0D0"),
+		SelectedDesc: lipgloss.Color("#81A1C1"),
+		Dimmed:       lipgloss.Color("#616E88"),
+	},
+	{
+		Name:         "dracula",
+		Header:       lipgloss.Color("#BD93F9"),
+		HeaderText:   lipgloss.Color("#282A36"),
+		Text:         lipgloss.Color("#50FA7B"),
+		Footer:       lipgloss.Color("#F8F8F2"),
+		Border:       lipgloss.Color("#6272A4"),
+		Selected:     lipgloss.Color("#FF79C6"),
+		SelectedDesc: lipgloss.Color("#BD93F9"),
+		Dimmed:       lipgloss.Color("#6272A4"),
+	},
+	{
+		// Solarized light, for terminals with a light background.
+		Name:         "solarized",
+		Header:       lipgloss.Color("#268BD2"),
+		HeaderText:   lipgloss.Color("#FDF6E3"),
+		Text:         lipgloss.Color("#586E75"),
+		Footer:       lipgloss.Color("#657B83"),
+		Border:       lipgloss.Color("#93A1A1"),
+		Selected:     lipgloss.Color("#CB4B16"),
+		SelectedDesc: lipgloss.Color("#B58900"),
+		Dimmed:       lipgloss.Color("#93A1A1"),
+	},
+	{
+		Name:         "mono",
+		Header:       lipgloss.NoColor{},
+		HeaderText:   lipgloss.NoColor{},
+		Text:         lipgloss.NoColor{},
+		Footer:       lipgloss.NoColor{},
+		Border:       lipgloss.NoColor{},
+		Selected:     lipgloss.NoColor{},
+		SelectedDesc: lipgloss.NoColor{},
+		Dimmed:       lipgloss.NoColor{},
+	},
+}
+
+// Theme new sessions start with, autoTheme picks by background.
+var defaultTheme = autoTheme
+
+const autoTheme = -1
+
+// name is one of Themes or "auto", which starts dark terminals on
+// "default" and light ones on "solarized".
+func SetDefaultTheme(name string) error {
+	if name == "auto" {
+		defaultTheme = autoTheme
+		return nil
+	}
+	names := []string{"auto"}
+	for i, t := range Themes {
+		if t.Name == name {
+			defaultTheme = i
+			return nil
+		}
+		names = append(names, t.Name)
+	}
+	return fmt.Errorf("unknown theme %q, want one of %s", name, strings.Join(names, ", "))
+}
+
+// Rebuilds every style from the session's renderer, styles made with the
+// global lipgloss renderer would use the server's color profile. The
+// renderer downgrades theme colors to what the terminal supports and
+// drops them for TERM=dumb or NO_COLOR.
+func (m *Model) applyTheme(i int) {
+	m.theme = i
+	t := Themes[i]
+	r := m.renderer
+	// Down to 8 or 16 colors most of a theme turns into something else or
+	// disappears, those terminals get bold and reverse video instead.
+	limited := m.limitedColor()
+	if limited {
+		t = colorless(t)
+	}
+
+	m.TxtStyle = r.NewStyle().Foreground(t.Text)
+	m.QuitStyle = r.NewStyle().Foreground(t.Footer)
+	m.HeaderStyle = r.NewStyle().Bold(true).Background(t.Header).Foreground(t.HeaderText).PaddingLeft(2)
+	// Colorless themes still set the header and footer apart.
+	m.TabStyle = m.HeaderStyle.UnsetPaddingLeft().Reverse(true)
+	if _, ok := t.Header.(lipgloss.NoColor); ok {
+		m.HeaderStyle = m.HeaderStyle.Reverse(true)
+		m.TabStyle = m.HeaderStyle.UnsetPaddingLeft().Underline(true)
+	}
+	if _, ok := t.Footer.(lipgloss.NoColor); ok && !limited {
+		m.QuitStyle = m.QuitStyle.Faint(true)
+	}
+	// Footer hints, the overlay keeps help's own look.
+	m.help.Styles.ShortKey = m.QuitStyle.Bold(true)
+	m.help.Styles.ShortDesc = m.QuitStyle
+	m.help.Styles.ShortSeparator = m.QuitStyle
+	m.help.Styles.Ellipsis = m.QuitStyle
+	m.BannerStyle = r.NewStyle().Foreground(t.Header)
+	m.MatchStyle = r.NewStyle().Reverse(true)
+	m.spinner.Style = r.NewStyle().Foreground(t.Selected)
+	m.viewport.Style = r.NewStyle().Border(m.border(lipgloss.RoundedBorder())).BorderForeground(t.Border)
+
+	// Same layout as list.NewDefaultDelegate, recolored.
+	d := list.NewDefaultDelegate()
+	d.Styles.NormalTitle = r.NewStyle().Padding(0, 0, 0, 2)
+	d.Styles.NormalDesc = d.Styles.NormalTitle.Foreground(t.Dimmed)
+	d.Styles.SelectedTitle = r.NewStyle().
+		Border(m.border(lipgloss.NormalBorder()), false, false, false, true).
+		BorderForeground(t.Selected).
+		Foreground(t.Selected).
+		Bold(limited).
+		Padding(0, 0, 0, 1)
+	d.Styles.SelectedDesc = d.Styles.SelectedTitle.Foreground(t.SelectedDesc)
+	d.Styles.DimmedTitle = r.NewStyle().Foreground(t.Dimmed).Padding(0, 0, 0, 2)
+	d.Styles.DimmedDesc = d.Styles.DimmedTitle
+	d.Styles.FilterMatch = r.NewStyle().Underline(true)
+	m.projectsList.SetDelegate(projectDelegate{DefaultDelegate: d, tags: r.NewStyle().Faint(true)})
+	m.inbox.SetDelegate(d)
+
+	m.refreshViewport()
+}
+
+// The list delegate with project tags set apart, in a style from the
+// session's renderer so they're plain where the terminal can't do better.
+type projectDelegate struct {
+	list.DefaultDelegate
+	tags lipgloss.Style
+}
+
+func (d projectDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if p, ok := item.(store.Project); ok && len(p.ProjectTags) > 0 {
+		item = taggedProject{Project: p, tags: d.tags}
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+type taggedProject struct {
+	store.Project
+	tags lipgloss.Style
+}
+
+func (p taggedProject) Description() string {
+	return p.Summary() + " " + p.tags.Render(p.TagList())
+}
+
+// Whether the terminal is Ascii or 8/16 colors, see applyTheme.
+func (m Model) limitedColor() bool {
+	p := m.renderer.ColorProfile()
+	return p == termenv.ANSI || p == termenv.Ascii
+}
+
+// b, or plain ASCII where box drawing characters may not draw, e.g. a
+// vt100.
+func (m Model) border(b lipgloss.Border) lipgloss.Border {
+	if m.limitedColor() {
+		return lipgloss.ASCIIBorder()
+	}
+	return b
+}
+
+// t without its colors, the name stays so prefs still remember it.
+func colorless(t Theme) Theme {
+	none := lipgloss.NoColor{}
+	return Theme{Name: t.Name, Header: none, HeaderText: none, Text: none, Footer: none,
+		Border: none, Selected: none, SelectedDesc: none, Dimmed: none}
+}
+
+func startTheme(r *lipgloss.Renderer) int {
+	if defaultTheme != autoTheme {
+		return defaultTheme
+	}
+	if !r.HasDarkBackground() {
+		for i, t := range Themes {
+			if t.Name == "solarized" {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+func (m *Model) nextTheme() {
+	m.applyTheme((m.theme + 1) % len(Themes))
+	m.refreshHome()
+}
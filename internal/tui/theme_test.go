@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+func modelWithProfile(t *testing.T, p termenv.Profile) Model {
+	t.Helper()
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(p)
+	return NewModel(Visitor{Term: "xterm", RemoteAddr: "192.0.2.1:1234", Username: "tester", Width: 100, Height: 30}, r)
+}
+
+// Tags are styled by the session's renderer, faint where it can and
+// plain text where it can't.
+func TestProjectTagsStyledPerSession(t *testing.T) {
+	tagged := store.Project{ProjectTitle: "Tagged", ProjectNumber: 1, ProjectContent: "write up", ProjectTags: []string{"go", "ssh"}}
+	for _, tt := range []struct {
+		profile termenv.Profile
+		faint   bool
+	}{
+		{termenv.Ascii, false},
+		{termenv.ANSI256, true},
+	} {
+		m := modelWithProfile(t, tt.profile)
+		m.projectsList.SetItems([]list.Item{tagged})
+		m = press(m, "p")
+		view := m.projectsList.View()
+		if !strings.Contains(view, "[go, ssh]") {
+			t.Fatalf("profile %v: tags missing from the list:\n%s", tt.profile, view)
+		}
+		if got := strings.Contains(view, "\x1b[2m[go, ssh]"); got != tt.faint {
+			t.Errorf("profile %v: faint tags %v, want %v", tt.profile, got, tt.faint)
+		}
+		if tt.profile == termenv.Ascii && strings.Contains(view, "\x1b[") {
+			t.Errorf("escape sequences in an Ascii session: %q", view)
+		}
+	}
+}
+
+// Screens with every kind of styled thing on them: tabs, borders, the
+// list's selection, the editor and its preview, search matches and the
+// help overlay.
+var profileScreens = []struct {
+	name  string
+	setup func(Model) Model
+}{
+	{"home", func(m Model) Model { return m }},
+	{"projects", func(m Model) Model { return press(m, "p") }},
+	{"project", func(m Model) Model { return press(m, "p", "enter") }},
+	{"search", func(m Model) Model { return press(typeText(press(m, "b", "/"), "the"), "enter") }},
+	{"editor", func(m Model) Model { return typeText(press(m, "m"), "hello there") }},
+	{"preview", func(m Model) Model { return press(typeText(press(m, "m"), "hello there"), "ctrl+s") }},
+	{"receipt", func(m Model) Model { return press(typeText(press(m, "m"), "hello there"), "ctrl+p") }},
+	{"help", func(m Model) Model { return press(m, "?") }},
+}
+
+// Parameters of every SGR sequence in s, e.g. "38;5;62".
+func sgrParams(s string) []string {
+	var params []string
+	for {
+		i := strings.Index(s, "\x1b[")
+		if i < 0 {
+			return params
+		}
+		s = s[i+2:]
+		end := strings.IndexFunc(s, func(r rune) bool { return r >= 0x40 && r <= 0x7e })
+		if end < 0 {
+			return params
+		}
+		if s[end] == 'm' {
+			params = append(params, s[:end])
+		}
+		s = s[end+1:]
+	}
+}
+
+// Whether an SGR sequence sets a 256 or 24-bit color.
+func extendedColor(params string) bool {
+	fields := strings.Split(params, ";")
+	for i, f := range fields {
+		if (f == "38" || f == "48" || f == "58") && i+1 < len(fields) && (fields[i+1] == "5" || fields[i+1] == "2") {
+			return true
+		}
+	}
+	return false
+}
+
+const boxDrawing = "╭╮╰╯─│┌┐└┘├┤┬┴┼"
+
+// Forced down to ANSI every theme keeps to the 16 colors with bold and
+// reverse video and ASCII borders, under Ascii nothing is styled at all,
+// and either way the screen is still laid out to the window.
+func TestViewUnderProfiles(t *testing.T) {
+	for theme := range Themes {
+		for _, sc := range profileScreens {
+			for _, p := range []termenv.Profile{termenv.TrueColor, termenv.ANSI256, termenv.ANSI, termenv.Ascii} {
+				m := modelWithProfile(t, p)
+				m.applyTheme(theme)
+				m = sc.setup(m)
+				view := m.View()
+				name := fmt.Sprintf("%s %s under %s", Themes[theme].Name, sc.name, p.Name())
+
+				extended := false
+				for _, params := range sgrParams(view) {
+					extended = extended || extendedColor(params)
+				}
+				switch p {
+				case termenv.ANSI:
+					if extended {
+						t.Errorf("%s: 256 or 24-bit color in an ANSI session: %q", name, view)
+					}
+				case termenv.Ascii:
+					if params := sgrParams(view); len(params) > 0 {
+						t.Errorf("%s: styling in an Ascii session: %q", name, params)
+					}
+				}
+				if p == termenv.ANSI || p == termenv.Ascii {
+					if strings.ContainsAny(view, boxDrawing) {
+						t.Errorf("%s: box drawing characters:\n%s", name, ansi.Strip(view))
+					}
+				}
+				if lines := strings.Split(view, "\n"); len(lines) != 30 {
+					t.Errorf("%s: %d lines, want 30", name, len(lines))
+				}
+				fitsWindow(t, name, view, 100)
+			}
+		}
+	}
+}
+
+// The default theme's 62 header is kept where the terminal has it.
+func TestViewKeepsColorWhereItCan(t *testing.T) {
+	for _, p := range []termenv.Profile{termenv.TrueColor, termenv.ANSI256} {
+		m := modelWithProfile(t, p)
+		m.applyTheme(0)
+		found := false
+		for _, params := range sgrParams(m.View()) {
+			found = found || extendedColor(params)
+		}
+		if !found {
+			t.Errorf("%s: no extended colors in the default theme", p.Name())
+		}
+	}
+}
+
+// The default theme's text follows the background so it's readable on
+// a light terminal too.
+func TestDefaultThemeTextFollowsBackground(t *testing.T) {
+	for _, tt := range []struct {
+		dark bool
+		want string
+	}{{true, "92"}, {false, "32"}} {
+		r := lipgloss.NewRenderer(io.Discard)
+		r.SetColorProfile(termenv.ANSI256)
+		r.SetHasDarkBackground(tt.dark)
+		m := NewModel(Visitor{Term: "xterm", RemoteAddr: "192.0.2.1:1234", Username: "tester", Width: 100, Height: 30}, r)
+		m.applyTheme(0)
+		if got := m.TxtStyle.Render("x"); !strings.Contains(got, "\x1b["+tt.want+"m") {
+			t.Errorf("dark background %v: text rendered %q, want color %s", tt.dark, got, tt.want)
+		}
+	}
+}
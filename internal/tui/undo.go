@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// How long a confirmed message can still be taken back with 'u'.
+const undoWindow = 10 * time.Second
+
+type undoTickMsg struct{ seq int }
+
+func undoTick(seq int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return undoTickMsg{seq: seq} })
+}
+
+// The message waiting out its undo window. Shared by every copy of the
+// Model so closing the session can still send it.
+type pendingSend struct {
+	mu   sync.Mutex
+	send func() (store.Message, error)
+}
+
+func (p *pendingSend) hold(send func() (store.Message, error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.send = send
+}
+
+// Returns the held send and clears it, nil if there wasn't one, so it
+// only ever runs once.
+func (p *pendingSend) take() func() (store.Message, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	send := p.send
+	p.send = nil
+	return send
+}
+
+// Sends a message still in its undo window, for when the session closes
+// before the countdown ends.
+func (m Model) commitPending() {
+	send := m.pending.take()
+	if send == nil {
+		return
+	}
+	if _, err := send(); err != nil {
+		log.Error("Could not save message on disconnect", "error", err, "user", m.username, "remote", m.remoteAddr)
+		return
+	}
+	log.Info("Message submitted on disconnect", "user", m.username, "remote", m.remoteAddr, "fingerprint", m.fingerprint)
+}
+
+// Whole seconds left to undo, rounded up.
+func (m Model) undoLeft() int {
+	return int(math.Ceil(m.undoDeadline.Sub(m.now()).Seconds()))
+}
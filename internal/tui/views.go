@@ -0,0 +1,383 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/will-x86/ssh-will-x86/internal/banner"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+func (m Model) View() string {
+	if m.disconnecting != "" {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.disconnecting)
+	}
+	if m.tooSmall() {
+		msg := fmt.Sprintf("please resize to at least %dx%d (current: %dx%d)", MinWidth, MinHeight, m.width, m.height)
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+			lipgloss.NewStyle().Width(max(m.width, 1)).Align(lipgloss.Center).Render(msg))
+	}
+
+	header := m.header()
+
+	contentHeight := m.height - HeaderHeight - FooterHeight
+	contentStyle := lipgloss.NewStyle().
+		Width(m.width).
+		Height(contentHeight)
+
+	var body string
+	switch m.State {
+	case StateHome, StateContact, StateBlog, StateStats, StateStatus, StateGuestbook:
+		body = m.fitOrScroll(contentStyle)
+	case StateProjects:
+		if m.inProjectsList {
+			body = contentStyle.Render(m.projectsList.View())
+		} else if m.selectedPost != nil {
+			body = contentStyle.Render(m.viewport.View())
+		}
+	case StateInbox:
+		if m.inInboxList {
+			body = contentStyle.Render(m.inbox.View())
+		} else {
+			body = contentStyle.Render(m.viewport.View())
+		}
+	case StateMessages:
+		body = contentStyle.
+			Align(lipgloss.Center, lipgloss.Top).
+			Render(m.messagesContent())
+	default:
+		body = contentStyle.
+			Align(lipgloss.Center, lipgloss.Center).
+			Render("Welcome! Use the controls below to navigate.")
+	}
+
+	if m.showHelp {
+		// Less the border and padding, columns that don't fit are dropped.
+		h := m.help
+		h.Width = max(m.width-6, 0)
+		box := lipgloss.NewStyle().
+			Border(m.border(lipgloss.RoundedBorder())).
+			Padding(1, 2).
+			Render("Keybindings\n\n" + h.FullHelpView(m.helpGroups()) + "\n\n? or esc to close")
+		body = lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Center, box)
+	}
+
+	controls := m.footerControls()
+	footer := lipgloss.NewStyle().
+		Width(m.width).
+		Height(FooterHeight).
+		AlignVertical(lipgloss.Bottom).
+		Render(controls)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Height(HeaderHeight).Render(header),
+		body,
+		footer,
+	)
+}
+
+// Key hints along the bottom, also used to map footer clicks to keys.
+// Always one line: the globals go first when they don't fit, then every
+// hint but "? for help", and anything else is cut short.
+func (m Model) footerControls() string {
+	ind := m.scrollIndicator()
+	if ind == "" {
+		ind = m.pageIndicator()
+	}
+	room := m.width
+	if ind != "" {
+		room -= ansi.StringWidth(ind) + 1
+	}
+
+	controls := m.footerStatus()
+	if controls == "" {
+		local, global := m.footerBindings()
+		if global == nil {
+			// ? types a question mark in the message editor, so as many
+			// of the editor's keys as fit instead.
+			h := m.help
+			h.Width = max(room, 0)
+			controls = h.ShortHelpView(local)
+		} else {
+			options := []string{m.help.ShortHelpView(append(append(global, local...), keys.Help))}
+			if local != nil {
+				options = append(options, m.help.ShortHelpView(append(local, keys.Help)))
+			}
+			options = append(options, m.QuitStyle.Render("? for help"))
+			for _, o := range options {
+				if controls = o; ansi.StringWidth(o) <= room {
+					break
+				}
+			}
+		}
+	}
+	controls = ansi.Truncate(controls, max(room, 0), "…")
+
+	if ind != "" {
+		gap := max(m.width-ansi.StringWidth(controls)-ansi.StringWidth(ind), 1)
+		controls += strings.Repeat(" ", gap) + m.QuitStyle.Render(ind)
+	}
+	return controls
+}
+
+// What's happening right now, shown instead of the key hints. Empty
+// when nothing is.
+func (m Model) footerStatus() string {
+	switch {
+	case m.quitPending:
+		prompt := "Press q again to quit"
+		if strings.TrimSpace(m.messageInput.Value()) != "" {
+			prompt += " • your unsent message will be lost!"
+		}
+		return m.QuitStyle.Render(prompt)
+	case m.toast != "":
+		return m.QuitStyle.Render(m.toast)
+	case m.commanding:
+		return m.commandInput.View() + "  " + m.help.ShortHelpView([]key.Binding{keys.RunCommand, keys.Cancel})
+	case m.commandErr != "":
+		return m.QuitStyle.Render(m.commandErr + " • try :home, :projects 2, :theme nord or :quit")
+	case m.State == StateProjects && m.inProjectsList && m.numBuf != "" && !m.projectsList.SettingFilter():
+		return m.QuitStyle.Render("Go to #" + m.numBuf + "_ (enter)")
+	case !m.canSearch():
+		return ""
+	case m.searching:
+		return m.searchInput.View() + "  " + m.help.ShortHelpView([]key.Binding{keys.RunSearch, keys.Cancel})
+	case m.shownLink > 0:
+		return m.linkFooter()
+	case m.query != "" && len(m.matches) == 0:
+		return m.QuitStyle.Render("Pattern not found: "+m.query+" • ") + m.help.ShortHelpView([]key.Binding{keys.ClearSearch})
+	case m.query != "":
+		return m.QuitStyle.Render(fmt.Sprintf("/%s match %d of %d • ", m.query, m.matchIdx+1, len(m.matches))) +
+			m.help.ShortHelpView([]key.Binding{keys.Next, keys.Prev, keys.ClearSearch})
+	}
+	return ""
+}
+
+// Bio plus the rotating daily bits.
+func (m Model) homeContent() string {
+	now := m.now()
+	home := homeBody() + "\n" + store.DailyQuote(now) + "\n"
+	if b := banner.Render("willx86.com", banner.SizeFor(m.width)); ShowBanner && b != "" {
+		home = "\n" + m.BannerStyle.Render(b) + "\n" + home
+	}
+	if i := store.FeaturedProject(now, m.projectsPosts); i >= 0 {
+		p := m.projectsPosts[i]
+		home += fmt.Sprintf("\nToday's featured project: #%d — %s (press Enter)\n", p.ProjectNumber, p.ProjectTitle)
+	}
+	return home
+}
+
+// Centered like a page when it fits, otherwise through the viewport so
+// it can be scrolled. Search matches are highlighted either way.
+func (m Model) fitOrScroll(style lipgloss.Style) string {
+	if m.scrolling() {
+		return style.Render(m.viewport.View())
+	}
+	return style.Align(lipgloss.Center, lipgloss.Center).Render(highlightMatches(m.viewportRaw, m.query, m.MatchStyle))
+}
+
+// Whether the body is being shown through the viewport.
+func (m Model) scrolling() bool {
+	switch m.State {
+	case StateProjects:
+		return !m.inProjectsList && m.selectedPost != nil
+	case StateInbox:
+		return !m.inInboxList
+	case StateHome, StateContact, StateBlog, StateStats, StateStatus, StateGuestbook:
+		return lipgloss.Height(m.viewportRaw) > m.height-HeaderHeight-FooterHeight ||
+			lipgloss.Width(m.viewportRaw) > m.width
+	}
+	return false
+}
+
+// less style position, only for states rendered through the viewport.
+func (m Model) scrollIndicator() string {
+	if !m.scrolling() {
+		return ""
+	}
+	switch {
+	case m.viewport.AtTop() && m.viewport.AtBottom():
+		return "All"
+	case m.viewport.AtTop():
+		return "Top"
+	case m.viewport.AtBottom():
+		return "Bot"
+	default:
+		return fmt.Sprintf("%d%%", int(m.viewport.ScrollPercent()*100))
+	}
+}
+
+// Which page of the projects list is showing, when there's more than one.
+func (m Model) pageIndicator() string {
+	p := m.projectsList.Paginator
+	if m.State != StateProjects || !m.inProjectsList || p.TotalPages <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("Page %d/%d", p.Page+1, p.TotalPages)
+}
+
+// When the last message went in and where it is in the print queue.
+func (m Model) sentDetails() string {
+	s := fmt.Sprintf("\nSent at %s (%s)\n", m.sent.Timestamp.Format("15:04:05 MST"), relativeTime(m.now().Sub(m.sent.Timestamp)))
+	if m.queuePos > 0 {
+		s += fmt.Sprintf("You are #%d in line to be printed\n", m.queuePos)
+	}
+	switch {
+	case m.sent.Public:
+		s += "It'll show in the guestbook once I've approved it\n"
+	case m.sentPublic:
+		s += "Links aren't allowed in the guestbook, so it was only sent to me\n"
+	}
+	return s
+}
+
+func relativeTime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return ago(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return ago(int(d.Hours()), "hour")
+	default:
+		return ago(int(d.Hours()/24), "day")
+	}
+}
+
+func ago(n int, unit string) string {
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
+
+func (m Model) messagesContent() string {
+	if m.readOnly {
+		return `
+Messages are disabled in the browser terminal.
+
+Answer the question above the terminal and reconnect,
+or use a real client: ssh willx86.com
+
+Press Esc or 'o' to return home.
+`
+	}
+	if m.holding() {
+		return fmt.Sprintf("\nMessage sending in %ds… press 'u' to undo\n", max(m.undoLeft(), 1))
+	}
+	if m.sending {
+		return "\n" + m.spinner.View() + " Sending your message...\n"
+	}
+	if m.messageSent {
+		return `
+Thank you for your message!
+` + m.sentDetails() + `
+It's currently making it's way through the internet.
+After that it'll be permanently burned into thermal receipt paper, on my desk
+
+See https://w.willx86.com/2025/11/06/printing-messages-from-my-site.html for more details ! 
+
+
+Press 'o' to return home or 'm' to send another message.
+`
+	}
+	if m.confirming {
+		preview := m.renderer.NewStyle().
+			Border(m.border(lipgloss.RoundedBorder())).
+			Padding(0, 1).
+			Width(messageWidth(m.width) + 2).
+			Render(strings.TrimSpace(m.messageInput.Value()))
+		return fmt.Sprintf(`
+Send this message?
+
+From: %s
+
+%s
+
+y/Enter to send | e to keep editing | Esc to cancel
+`, m.username, preview)
+	}
+	if m.editingName {
+		return fmt.Sprintf(`
+(Currently printer is deactivated, I'm moving house !)
+Leave a message 
+
+Change your name:
+%s
+
+Press Enter to confirm | Esc to cancel
+`, m.nameInput.View())
+	}
+	return fmt.Sprintf(`
+Leave a message 
+
+Signed in as: %s
+%s
+%s
+%d/%d
+%s%s%s%s
+Press Ctrl+N to change name | Ctrl+P to preview | Ctrl+S to send
+Ctrl+U to clear | Esc to go back, your draft is kept
+`, m.username, m.sendNotice(), m.messageInput.View(), utf8.RuneCountInString(m.messageInput.Value()), store.MaxMessageLength, m.vimMode(), m.receiptPreview(), m.publicToggle(), m.draftHint())
+}
+
+// Why the last send didn't go, above the editor so the draft is still
+// there to fix or retry.
+func (m Model) sendNotice() string {
+	var notice string
+	switch {
+	case errors.Is(m.sendErr, store.ErrRateLimited):
+		notice = "You've sent a lot of messages, please wait a few minutes before sending another."
+	case errors.Is(m.sendErr, store.ErrMailboxFull):
+		notice = "The mailbox is full, the printer needs to catch up. Please try again later."
+	case errors.Is(m.sendErr, store.ErrUnprintable), errors.Is(m.sendErr, store.ErrNameTooLong):
+		notice = fmt.Sprintf("Can't send that: %v.", m.sendErr)
+	case m.sendErr != nil:
+		notice = "Sorry, something went wrong sending your message, Ctrl+S to try again."
+	case m.tooLong:
+		notice = "Your message is too long and or has too many newlines :) paper's expensive yaknow?"
+	default:
+		return ""
+	}
+	return "\n" + notice + "\n"
+}
+
+func (m Model) draftHint() string {
+	if m.draft == "" || m.messageInput.Value() != "" {
+		return ""
+	}
+	return "Press Ctrl+R to restore your draft\n"
+}
+
+// Checkbox for ctrl+g, only when there's a guestbook to go in.
+func (m Model) publicToggle() string {
+	if !GuestbookEnabled {
+		return ""
+	}
+	box := "[ ]"
+	if m.public {
+		box = "[x]"
+	}
+	return box + " Also show in the public guestbook (Ctrl+G)\n"
+}
+
+// The draft as it'll come out of the printer, hard wrapped at
+// ReceiptWidth so spacing and ASCII art look the same as on paper.
+// Widths are cells per grapheme, so CJK and emoji count as two the same
+// as in the box lipgloss draws around them.
+func (m Model) receiptPreview() string {
+	if !m.showReceipt {
+		return ""
+	}
+	text := ansi.Hardwrap(m.messageInput.Value(), ReceiptWidth, true)
+	return m.renderer.NewStyle().
+		Border(m.border(lipgloss.NormalBorder())).
+		Width(ReceiptWidth).
+		Render(text) + "\n"
+}
@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/will-x86/ssh-will-x86/internal/banner"
+)
+
+var wideMessages = map[string]string{
+	"cjk":     strings.Repeat("日本語のメッセージ", 8),
+	"emoji":   strings.Repeat("👋🏽🎉❤️", 15),
+	"zwj":     strings.Repeat("👩‍👩‍👧 ", 12),
+	"flags":   "x" + strings.Repeat("🇬🇧🇯🇵", 12),
+	"mixed":   "hello 世界 " + strings.Repeat("a日b👋", 10),
+	"odd gap": "a" + strings.Repeat("漢", 40),
+}
+
+// Wide runes mustn't push the editor, preview or receipt past the
+// window or leave their borders ragged.
+func TestMessagesWideRunes(t *testing.T) {
+	for name, text := range wideMessages {
+		for _, width := range []int{60, 61, 80} {
+			m := newTestModel(t, width, 40)
+			m = press(m, "m")
+			m = typeText(m, text)
+			if got := m.messageInput.Value(); got != text {
+				t.Fatalf("%s: editor has %q", name, got)
+			}
+
+			m = press(m, "ctrl+p")
+			fitsWindow(t, name+" editor", m.View(), width)
+			sameWidth(t, name+" editor", m.messageInput.View())
+			for _, line := range strings.Split(m.messageInput.View(), "\n") {
+				if w := ansi.StringWidth(line); w > messageWidth(width)+6 {
+					t.Errorf("%s: editor line %d cells wide, text should wrap at %d", name, w, messageWidth(width))
+				}
+			}
+
+			receipt := strings.TrimSuffix(m.receiptPreview(), "\n")
+			sameWidth(t, name+" receipt", receipt)
+			if w := ansi.StringWidth(strings.SplitN(receipt, "\n", 2)[0]); w != ReceiptWidth+2 {
+				t.Errorf("%s: receipt is %d cells, want %d", name, w, ReceiptWidth+2)
+			}
+			if got := boxText(receipt); got != strings.ReplaceAll(text, " ", "") {
+				t.Errorf("%s: receipt lost or split text:\n%s", name, receipt)
+			}
+
+			m = press(m, "ctrl+s")
+			if !m.confirming {
+				t.Fatalf("%s: not confirming", name)
+			}
+			fitsWindow(t, name+" preview", m.View(), width)
+		}
+	}
+}
+
+func fitsWindow(t *testing.T, name, view string, width int) {
+	t.Helper()
+	for _, line := range strings.Split(view, "\n") {
+		if w := ansi.StringWidth(line); w > width {
+			t.Errorf("%s: %d cells in a %d wide window: %q", name, w, width, ansi.Strip(line))
+		}
+	}
+}
+
+func sameWidth(t *testing.T, name, block string) {
+	t.Helper()
+	lines := strings.Split(block, "\n")
+	for _, line := range lines[1:] {
+		if ansi.StringWidth(line) != ansi.StringWidth(lines[0]) {
+			t.Errorf("%s: ragged lines\n%s", name, ansi.Strip(block))
+			return
+		}
+	}
+}
+
+// The text inside a box, borders and spaces removed.
+func boxText(box string) string {
+	var b strings.Builder
+	lines := strings.Split(ansi.Strip(box), "\n")
+	for _, line := range lines[1 : len(lines)-1] {
+		line = strings.TrimPrefix(strings.TrimSuffix(line, "|"), "|")
+		b.WriteString(strings.ReplaceAll(line, " ", ""))
+	}
+	return b.String()
+}
+
+// Home picks the banner for the window and redraws it on a resize.
+func TestHomeBannerForWidth(t *testing.T) {
+	for _, tt := range []struct {
+		width int
+		want  banner.Size
+	}{{59, banner.None}, {60, banner.Compact}, {99, banner.Compact}, {100, banner.Full}} {
+		m := newTestModel(t, 120, 40)
+		m = send(m, tea.WindowSizeMsg{Width: tt.width, Height: 40})
+		for _, s := range []banner.Size{banner.Compact, banner.Full} {
+			b := strings.Split(banner.Render("willx86.com", s), "\n")[0]
+			if got := strings.Contains(m.viewportRaw, b); got != (s == tt.want) {
+				t.Errorf("%d columns: size %v banner shown %v, want size %v", tt.width, s, got, tt.want)
+			}
+		}
+		fitsWindow(t, fmt.Sprintf("home at %d columns", tt.width), m.View(), tt.width)
+	}
+}
+
+// The footer is one line however narrow the window, the key hints give
+// way first and the scroll or page indicator stays on the right. Below
+// MinWidth only the resize notice is drawn, but the footer's still
+// checked down to 40 columns.
+func TestFooterOneLine(t *testing.T) {
+	const height = 24
+	for _, tt := range []struct {
+		name  string
+		setup func(Model) Model
+	}{
+		{"home", func(m Model) Model { return m }},
+		{"projects list", func(m Model) Model { return press(m, "p") }},
+		{"projects filter", func(m Model) Model { return filterKey(press(m, "p"), "/") }},
+		{"projects number", func(m Model) Model { return press(m, "p", "1") }},
+		{"project detail", func(m Model) Model { return press(m, "p", "enter") }},
+		{"project link", func(m Model) Model { return press(m, "p", "enter", "enter") }},
+		{"search", func(m Model) Model { return typeText(press(m, "b", "/"), "a very long search query indeed") }},
+		{"search not found", func(m Model) Model {
+			return press(typeText(press(m, "b", "/"), "no such words anywhere at all"), "enter")
+		}},
+		{"blog", func(m Model) Model { return press(m, "b") }},
+		{"contact", func(m Model) Model { return press(m, "c") }},
+		{"message editor", func(m Model) Model { return press(m, "m") }},
+		{"quit pending", func(m Model) Model { return press(typeText(press(m, "m"), "draft"), "esc", "q") }},
+		{"command", func(m Model) Model { return typeText(press(m, ":"), "projects 12345678901234567890") }},
+		{"command error", func(m Model) Model { return press(typeText(press(m, ":"), "nonsense"), "enter") }},
+		{"help", func(m Model) Model { return press(m, "?") }},
+	} {
+		for _, width := range []int{40, 50, MinWidth, 80, 120} {
+			name := fmt.Sprintf("%s at %d columns", tt.name, width)
+			m := tt.setup(newTestModel(t, 120, height))
+			m = send(m, tea.WindowSizeMsg{Width: width, Height: height})
+
+			controls := m.footerControls()
+			if strings.Contains(controls, "\n") {
+				t.Errorf("%s: footer wraps: %q", name, ansi.Strip(controls))
+			}
+			if w := ansi.StringWidth(controls); w > width {
+				t.Errorf("%s: footer is %d cells wide: %q", name, w, ansi.Strip(controls))
+			}
+			if strings.TrimSpace(ansi.Strip(controls)) == "" {
+				t.Errorf("%s: empty footer", name)
+			}
+			if width < MinWidth {
+				continue
+			}
+			view := m.View()
+			if lines := strings.Split(view, "\n"); len(lines) != height {
+				t.Errorf("%s: view is %d lines, want %d", name, len(lines), height)
+			} else if last := ansi.Strip(lines[height-1]); strings.TrimSpace(last) != strings.TrimSpace(ansi.Strip(controls)) {
+				t.Errorf("%s: last line %q, want the footer %q", name, last, ansi.Strip(controls))
+			}
+			fitsWindow(t, name, view, width)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Vim style modes in the message editor, see updateVim.
+var VimInput bool
+
+// Normal mode keys while VimInput is on, reports false for keys the
+// editor handles as usual. The editor starts in insert mode and esc
+// only leaves the page from normal mode.
+func (m Model) updateVim(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	if !VimInput {
+		return m, nil, false
+	}
+	if !m.vimNormal {
+		if msg.String() == "esc" {
+			m.vimNormal = true
+			return m, nil, true
+		}
+		return m, nil, false
+	}
+	if msg.Type != tea.KeyRunes || msg.Alt {
+		m.vimPending = ""
+		// ctrl+s and friends work in both modes, the arrows still move and
+		// tab still switches tabs.
+		switch msg.Type {
+		case tea.KeyEsc, tea.KeyTab, tea.KeyShiftTab, tea.KeyLeft, tea.KeyRight, tea.KeyUp, tea.KeyDown:
+			return m, nil, false
+		}
+		return m, nil, !strings.HasPrefix(msg.String(), "ctrl+")
+	}
+
+	key := m.vimPending + msg.String()
+	m.vimPending = ""
+	move := func(k tea.KeyMsg) {
+		m.messageInput, _ = m.messageInput.Update(k)
+	}
+	switch key {
+	case "i":
+		m.vimNormal = false
+	case "a":
+		move(tea.KeyMsg{Type: tea.KeyRight})
+		m.vimNormal = false
+	case "A":
+		m.messageInput.CursorEnd()
+		m.vimNormal = false
+	case "o":
+		m.messageInput.CursorEnd()
+		m.messageInput.InsertRune('\n')
+		m.vimNormal = false
+	case "h":
+		move(tea.KeyMsg{Type: tea.KeyLeft})
+	case "l":
+		move(tea.KeyMsg{Type: tea.KeyRight})
+	case "j":
+		m.messageInput.CursorDown()
+	case "k":
+		m.messageInput.CursorUp()
+	case "w":
+		move(tea.KeyMsg{Type: tea.KeyRight, Alt: true})
+	case "b":
+		move(tea.KeyMsg{Type: tea.KeyLeft, Alt: true})
+	case "0":
+		m.messageInput.CursorStart()
+	case "$":
+		m.messageInput.CursorEnd()
+	case "x":
+		move(tea.KeyMsg{Type: tea.KeyDelete})
+	case "d":
+		m.vimPending = "d"
+	case "dd":
+		m.deleteLine()
+	}
+	return m, nil, true
+}
+
+// Removes the line the cursor is on, leaving it at the start of the next.
+func (m *Model) deleteLine() {
+	lines := strings.Split(m.messageInput.Value(), "\n")
+	row := m.messageInput.Line()
+	if row >= len(lines) {
+		return
+	}
+	lines = append(lines[:row], lines[row+1:]...)
+	m.messageInput.SetValue(strings.Join(lines, "\n"))
+	if len(lines) == 0 {
+		return
+	}
+	for m.messageInput.Line() > min(row, len(lines)-1) {
+		m.messageInput.CursorUp()
+	}
+	m.messageInput.CursorStart()
+}
+
+func (m Model) vimMode() string {
+	switch {
+	case !VimInput:
+		return ""
+	case m.vimNormal:
+		return "-- NORMAL --\n"
+	default:
+		return "-- INSERT --\n"
+	}
+}
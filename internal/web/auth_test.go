@@ -0,0 +1,131 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Sets the secrets the handlers accept for a test.
+func useSecrets(t *testing.T, sk string) {
+	t.Helper()
+	saved, savedQuery := secretKeys, AllowQuerySecret
+	secretKeys, AllowQuerySecret = SplitSecrets(sk), true
+	t.Cleanup(func() { secretKeys, AllowQuerySecret = saved, savedQuery })
+}
+
+// Captures the default logger's output for a test.
+func captureLog(t *testing.T) *logBuffer {
+	t.Helper()
+	buf := &logBuffer{}
+	log.SetOutput(buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return buf
+}
+
+// A buffer background goroutines can log to while the test reads it.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Queues a message named for the test, the duplicate filter would hold
+// back the same text sent twice.
+func queueOne(t *testing.T) {
+	t.Helper()
+	if _, err := store.AddMessage("w", t.Name(), "192.0.2.1:1", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fetchLatest(t *testing.T, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestLatestAuth(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		target string
+		header http.Header
+	}{
+		{"bearer", "/messages/latest", http.Header{"Authorization": {"Bearer new-key"}}},
+		{"bearer padded", "/messages/latest", http.Header{"Authorization": {"Bearer  old-key "}}},
+		{"x-secret", "/messages/latest", http.Header{"X-Secret": {"old-key"}}},
+		{"query", "/messages/latest?secret=new-key", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			useSecrets(t, "old-key, new-key")
+			resetMessages(t)
+			queueOne(t)
+			w := fetchLatest(t, tc.target, tc.header)
+			if want := "w---" + t.Name() + "---"; w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), want) {
+				t.Fatalf("got %d %q, want 200 %q...", w.Code, w.Body.String(), want)
+			}
+			if len(store.Messages()) != 0 {
+				t.Error("printed message left in the queue")
+			}
+		})
+	}
+}
+
+func TestLatestRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		secrets string
+		target  string
+		header  http.Header
+		noQuery bool
+	}{
+		{"no secret", "key", "/messages/latest", nil, false},
+		{"wrong bearer", "key", "/messages/latest", http.Header{"Authorization": {"Bearer guess"}}, false},
+		{"prefix of the key", "key", "/messages/latest", http.Header{"X-Secret": {"ke"}}, false},
+		{"not bearer", "key", "/messages/latest", http.Header{"Authorization": {"Basic key"}}, false},
+		{"wrong query", "key", "/messages/latest?secret=guess", nil, false},
+		{"query turned off", "key", "/messages/latest?secret=key", nil, true},
+		{"none configured", "", "/messages/latest", http.Header{"Authorization": {"Bearer "}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			useSecrets(t, tc.secrets)
+			AllowQuerySecret = !tc.noQuery
+			resetMessages(t)
+			queueOne(t)
+			logs := captureLog(t)
+			w := fetchLatest(t, tc.target, tc.header)
+			if w.Code != http.StatusUnauthorized || w.Body.Len() != 0 {
+				t.Fatalf("got %d %q, want an empty 401", w.Code, w.Body.String())
+			}
+			if len(store.Messages()) != 1 {
+				t.Error("rejected fetch took the message")
+			}
+			if strings.Contains(logs.String(), "guess") {
+				t.Errorf("supplied secret logged: %s", logs)
+			}
+		})
+	}
+}
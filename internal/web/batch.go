@@ -0,0 +1,54 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Most messages one GET /messages?limit= returns.
+const maxPage = 100
+
+// GET /messages?limit=N&after=<id>, a page of the queue for printers
+// fetching in batches, nothing is removed until it's DELETEd.
+func pageHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := maxPage
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxPage)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(store.MessagesAfter(q.Get("after"), limit))
+}
+
+// DELETE /messages/<id>, acks a message fetched from GET /messages once
+// it's printed.
+func ackHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !Authorized(r) {
+		log.Warn("Unauthorized message delete", "remote", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/messages/")
+	if id == "" || strings.Contains(id, "/") || !store.DeleteByID(id) {
+		http.NotFound(w, r)
+		return
+	}
+	stats.MessagesFetched.Inc("memory")
+	log.Info("Message acked", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+func api(t *testing.T, method, target, secret string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, target, nil)
+	if secret != "" {
+		r.Header.Set("Authorization", "Bearer "+secret)
+	}
+	w := httptest.NewRecorder()
+	if strings.HasPrefix(target, "/messages/") {
+		ackHandler(w, r)
+	} else {
+		listHandler(w, r)
+	}
+	return w
+}
+
+func page(t *testing.T, query string) []string {
+	t.Helper()
+	w := api(t, http.MethodGet, "/messages?"+query, "key")
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: got %d %s", query, w.Code, w.Body)
+	}
+	var msgs []store.Message
+	if err := json.Unmarshal(w.Body.Bytes(), &msgs); err != nil {
+		t.Fatalf("%s: %v\n%s", query, err, w.Body)
+	}
+	if msgs == nil {
+		t.Fatalf("%s: null instead of an empty list", query)
+	}
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func queuedIDs() []string {
+	var ids []string
+	for _, m := range store.Messages() {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func sameIDs(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d messages %v, want %v", name, len(got), got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestPageBoundaries(t *testing.T) {
+	useSecrets(t, "key")
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	fill(t, 5)
+	ids := queuedIDs()
+
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"limit=2", ids[:2]},
+		{"limit=2&after=" + ids[1], ids[2:4]},
+		{"limit=2&after=" + ids[3], ids[4:]},
+		{"limit=2&after=" + ids[4], nil},
+		{"limit=5", ids},
+		{"limit=6", ids},
+		{"limit=1&after=" + ids[0], ids[1:2]},
+		{"after=" + ids[2], ids[3:]},
+		{"after=", ids},
+		{"limit=&after=", ids},
+		{"after=0", ids},
+		{"after=ffffffffffffffff", nil},
+	} {
+		sameIDs(t, tt.query, page(t, tt.query), tt.want)
+	}
+	sameIDs(t, "paging leaves the queue", queuedIDs(), ids)
+}
+
+func TestPageAfterAcked(t *testing.T) {
+	useSecrets(t, "key")
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	fill(t, 4)
+	ids := queuedIDs()
+
+	if w := api(t, http.MethodDelete, "/messages/"+ids[1], "key"); w.Code != http.StatusNoContent {
+		t.Fatalf("ack: got %d", w.Code)
+	}
+	sameIDs(t, "after an acked ID", page(t, "limit=10&after="+ids[1]), ids[2:])
+	sameIDs(t, "first page", page(t, "limit=2"), []string{ids[0], ids[2]})
+}
+
+func TestPageCapped(t *testing.T) {
+	useSecrets(t, "key")
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	fill(t, maxPage+5)
+	ids := queuedIDs()
+
+	sameIDs(t, "over the cap", page(t, "limit=1000"), ids[:maxPage])
+	sameIDs(t, "no limit", page(t, "after="), ids[:maxPage])
+	sameIDs(t, "next page", page(t, "limit=1000&after="+ids[maxPage-1]), ids[maxPage:])
+}
+
+func TestPageRejects(t *testing.T) {
+	useSecrets(t, "key")
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	fill(t, 1)
+	for _, q := range []string{"limit=0", "limit=-1", "limit=x", "limit=1.5"} {
+		if w := api(t, http.MethodGet, "/messages?"+q, "key"); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", q, w.Code)
+		}
+	}
+	if w := api(t, http.MethodGet, "/messages?limit=1", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no secret: got %d, want 401", w.Code)
+	}
+	if w := api(t, http.MethodGet, "/messages?limit=1", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: got %d, want 401", w.Code)
+	}
+}
+
+func TestAck(t *testing.T) {
+	useSecrets(t, "key")
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	fill(t, 3)
+	ids := queuedIDs()
+
+	if w := api(t, http.MethodDelete, "/messages/"+ids[1], "key"); w.Code != http.StatusNoContent {
+		t.Fatalf("ack: got %d", w.Code)
+	}
+	sameIDs(t, "after the ack", queuedIDs(), []string{ids[0], ids[2]})
+	if got := store.Queued(); got != 2 {
+		t.Errorf("queue length %d after the ack, want 2", got)
+	}
+
+	for _, tt := range []struct {
+		name, method, target, secret string
+		want                         int
+	}{
+		{"acked twice", http.MethodDelete, "/messages/" + ids[1], "key", http.StatusNotFound},
+		{"never queued", http.MethodDelete, "/messages/0000000000000000", "key", http.StatusNotFound},
+		{"no ID", http.MethodDelete, "/messages/", "key", http.StatusNotFound},
+		{"nested path", http.MethodDelete, "/messages/" + ids[0] + "/x", "key", http.StatusNotFound},
+		{"no secret", http.MethodDelete, "/messages/" + ids[0], "", http.StatusUnauthorized},
+		{"wrong secret", http.MethodDelete, "/messages/" + ids[0], "wrong", http.StatusUnauthorized},
+		{"GET", http.MethodGet, "/messages/" + ids[0], "key", http.StatusMethodNotAllowed},
+	} {
+		w := api(t, tt.method, tt.target, tt.secret)
+		if w.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.want)
+		}
+		if tt.want == http.StatusMethodNotAllowed && w.Header().Get("Allow") != http.MethodDelete {
+			t.Errorf("%s: Allow %q", tt.name, w.Header().Get("Allow"))
+		}
+	}
+	sameIDs(t, "after the rejected acks", queuedIDs(), []string{ids[0], ids[2]})
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Origin a browser dashboard may read the message endpoints from, or
+// "*" for any. Empty (the default) sends no CORS headers at all.
+var CORSOrigin string
+
+// How long a browser can reuse a preflight answer.
+const corsMaxAge = 10 * time.Minute
+
+// Adds CORS headers for CORSOrigin and answers OPTIONS preflights itself,
+// before the secret is checked, browsers don't send it with those.
+func cors(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if CORSOrigin == "" || origin == "" {
+			h(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if CORSOrigin != "*" && origin != CORSOrigin {
+			h(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", CORSOrigin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, X-Secret, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// GET /messages/export?format=csv|json, every stored message without
+// removing any. JSON is the default.
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	if !Authorized(r) {
+		log.Warn("Unauthorized message export", "remote", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	msgs := store.Messages()
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="messages.json"`)
+		_ = json.NewEncoder(w).Encode(msgs)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="messages.csv"`)
+		// encoding/csv quotes fields with commas, quotes and newlines.
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"id", "from", "content", "timestamp", "remote_addr", "fingerprint"})
+		for _, m := range msgs {
+			_ = cw.Write([]string{m.ID, m.From, m.Content, m.Timestamp.Format(time.RFC3339), m.RemoteAddr, m.Fingerprint})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			log.Error("Writing CSV export failed", "error", err)
+		}
+	default:
+		http.Error(w, "format must be csv or json", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+func export(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/messages/export"+query, nil)
+	r.Header.Set("Authorization", "Bearer key")
+	w := httptest.NewRecorder()
+	exportHandler(w, r)
+	return w
+}
+
+var awkward = []struct{ from, content string }{
+	{"Smith, J", "plain"},
+	{"will", `she said "hi", then left`},
+	{"will", "line one\nline two\n\nline four"},
+	{`"quoted"`, `,,"",` + "\n" + `trailing "`},
+	{"will", "unicode é, 日本 👋"},
+}
+
+func TestExportCSVQuoting(t *testing.T) {
+	useSecrets(t, "key")
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	var want []store.Message
+	for _, a := range awkward {
+		msg, err := store.AddMessage(a.from, a.content, "192.0.2.1:1", "SHA256:abc")
+		if err != nil {
+			t.Fatalf("%q: %v", a.content, err)
+		}
+		want = append(want, msg)
+	}
+
+	w := export(t, "?format=csv")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, raw := range []string{
+		`"Smith, J"`,
+		`"she said ""hi"", then left"`,
+		"\"line one\nline two\n\nline four\"",
+		`"""quoted"""`,
+	} {
+		if !strings.Contains(body, raw) {
+			t.Errorf("CSV doesn't quote it as %s:\n%s", raw, body)
+		}
+	}
+
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("export isn't valid CSV: %v\n%s", err, body)
+	}
+	if len(records) != len(want)+1 {
+		t.Fatalf("%d records, want a header and %d", len(records), len(want))
+	}
+	if got := strings.Join(records[0], ","); got != "id,from,content,timestamp,remote_addr,fingerprint" {
+		t.Errorf("header %q", got)
+	}
+	for i, m := range want {
+		row := records[i+1]
+		if got := []string{row[0], row[1], row[2], row[4], row[5]}; strings.Join(got, "|") != strings.Join([]string{m.ID, m.From, m.Content, m.RemoteAddr, m.Fingerprint}, "|") {
+			t.Errorf("row %d = %q, want %+v", i, row, m)
+		}
+		if ts, err := time.Parse(time.RFC3339, row[3]); err != nil || !ts.Equal(m.Timestamp.Truncate(time.Second)) {
+			t.Errorf("row %d timestamp %q: %v", i, row[3], err)
+		}
+	}
+	if len(store.Messages()) != len(want) {
+		t.Error("export removed messages")
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	useSecrets(t, "key")
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	for _, a := range awkward {
+		if _, err := store.AddMessage(a.from, a.content, "192.0.2.1:1", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := export(t, "")
+	var got []store.Message
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(awkward) {
+		t.Fatalf("%d messages, want %d", len(got), len(awkward))
+	}
+	for i, a := range awkward {
+		if got[i].From != a.from || got[i].Content != a.content {
+			t.Errorf("message %d = %q %q, want %q %q", i, got[i].From, got[i].Content, a.from, a.content)
+		}
+	}
+}
+
+func TestExportRejects(t *testing.T) {
+	useSecrets(t, "key")
+	if w := export(t, "?format=xml"); w.Code != http.StatusBadRequest {
+		t.Errorf("format=xml: %d, want 400", w.Code)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/messages/export?format=csv", nil)
+	w := httptest.NewRecorder()
+	exportHandler(w, r)
+	if w.Code != http.StatusUnauthorized || w.Body.Len() != 0 {
+		t.Errorf("no secret: %d %q, want an empty 401", w.Code, w.Body.String())
+	}
+}
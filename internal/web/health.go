@@ -0,0 +1,53 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Which listeners are accepting connections, /readyz is 503 until both
+// are. The SSH side is set from main.
+type Status struct {
+	SSH atomic.Bool
+	Web atomic.Bool
+}
+
+var Readiness Status
+
+func (s *Status) Ready() bool {
+	return s.SSH.Load() && s.Web.Load()
+}
+
+type health struct {
+	Status         string `json:"status"`
+	Uptime         string `json:"uptime"`
+	Version        string `json:"version"`
+	ActiveSessions int64  `json:"active_sessions"`
+	Messages       int64  `json:"messages"` // waiting for the printer
+}
+
+// GET /healthz, no secret needed so proxies and watchdogs can probe it.
+// Only reads counters, a busy message store can't hold it up.
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(health{
+		Status:         "ok",
+		Uptime:         store.Uptime().Round(time.Second).String(),
+		Version:        store.Version,
+		ActiveSessions: store.ActiveSessions(),
+		Messages:       store.Queued(),
+	})
+}
+
+// GET /readyz
+func readyHandler(w http.ResponseWriter, r *http.Request) {
+	if !Readiness.Ready() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok\n"))
+}
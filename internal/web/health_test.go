@@ -0,0 +1,125 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func readyz() int {
+	w := httptest.NewRecorder()
+	readyHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return w.Code
+}
+
+// Polls cond until it holds or a second has passed.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestReadyzNeedsBothListeners(t *testing.T) {
+	sshUp, webUp := Readiness.SSH.Load(), Readiness.Web.Load()
+	t.Cleanup(func() { Readiness.SSH.Store(sshUp); Readiness.Web.Store(webUp) })
+
+	for _, tt := range []struct {
+		ssh, web bool
+		want     int
+	}{
+		{false, false, http.StatusServiceUnavailable},
+		{true, false, http.StatusServiceUnavailable},
+		{false, true, http.StatusServiceUnavailable},
+		{true, true, http.StatusOK},
+	} {
+		Readiness.SSH.Store(tt.ssh)
+		Readiness.Web.Store(tt.web)
+		if got := readyz(); got != tt.want {
+			t.Errorf("ssh %v, web %v: %d, want %d", tt.ssh, tt.web, got, tt.want)
+		}
+	}
+}
+
+// Start registers on the default mux, which panics the second time
+// round under -count.
+var webServerStarted atomic.Bool
+
+// Against the real webserver: 503 while SSH isn't listening, ready once
+// it is, and not ready again after Shutdown.
+func TestReadyzWebServer(t *testing.T) {
+	if webServerStarted.Swap(true) {
+		t.Skip("the webserver can only be started once per process")
+	}
+	sshUp, webUp := Readiness.SSH.Load(), Readiness.Web.Load()
+	t.Cleanup(func() { Readiness.SSH.Store(sshUp); Readiness.Web.Store(webUp) })
+	Readiness.SSH.Store(false)
+	Readiness.Web.Store(false)
+	if got := readyz(); got != http.StatusServiceUnavailable {
+		t.Fatalf("before anything listens: %d, want 503", got)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	ln.Close()
+	useSecrets(t, "key")
+	srv := Start("127.0.0.1", port, "key", "", "")
+	base := "http://127.0.0.1:" + port
+
+	// No keep-alives, a spare connection the client dialed but never
+	// used would hold Shutdown up.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	get := func(path string) int {
+		resp, err := client.Get(base + path)
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	eventually(t, "the webserver to listen", func() bool { return get("/healthz") == http.StatusOK })
+	if !Readiness.Web.Load() {
+		t.Error("listening but Web not ready")
+	}
+	if got := get("/readyz"); got != http.StatusServiceUnavailable {
+		t.Errorf("web up, ssh down: %d, want 503", got)
+	}
+
+	Readiness.SSH.Store(true)
+	if got := get("/readyz"); got != http.StatusOK {
+		t.Errorf("both up: %d, want 200", got)
+	}
+
+	resp, err := client.Get(base + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h health
+	err = json.NewDecoder(resp.Body).Decode(&h)
+	resp.Body.Close()
+	if err != nil || h.Status != "ok" {
+		t.Errorf("healthz: %+v, %v", h, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "Web to go unready", func() bool { return !Readiness.Web.Load() })
+	if got := readyz(); got != http.StatusServiceUnavailable {
+		t.Errorf("after Shutdown: %d, want 503", got)
+	}
+}
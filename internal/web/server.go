@@ -0,0 +1,224 @@
+package web
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/metrics"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Any of these is accepted, see SplitSecrets.
+var secretKeys []string
+
+// Deprecated ?secret= support, on until every client sends a header.
+var AllowQuerySecret = true
+
+var querySecretWarning sync.Once
+
+// Unscraped until UseMetrics swaps in the real registry.
+var stats = metrics.New(metrics.NewRegistry())
+
+func UseMetrics(m *metrics.Metrics) {
+	stats = m
+}
+
+// Times the webserver tries to (re)listen before exiting.
+const listenAttempts = 5
+
+// Starts the webserver on host:port in the background, with TLS if
+// LoadCertificate was given a pair. sk may hold several secrets, see
+// SplitSecrets. Call Shutdown on the result to stop it.
+func Start(host, port, sk, wURL, wSecret string) *http.Server {
+	secretKeys = SplitSecrets(sk)
+	store.UseWorker(wURL, wSecret)
+	http.HandleFunc("/messages/latest", stats.Instrument("/messages/latest", recoverWrap(cors(handler))))
+	http.HandleFunc("/messages/wait", stats.Instrument("/messages/wait", recoverWrap(cors(waitHandler))))
+	http.HandleFunc("/messages", stats.Instrument("/messages", recoverWrap(cors(listHandler))))
+	http.HandleFunc("/messages/", stats.Instrument("/messages/{id}", recoverWrap(cors(ackHandler))))
+	http.HandleFunc("/messages/export", stats.Instrument("/messages/export", recoverWrap(cors(exportHandler))))
+	http.HandleFunc("/stats", stats.Instrument("/stats", recoverWrap(statsHandler)))
+	http.HandleFunc("/healthz", healthHandler)
+	http.HandleFunc("/readyz", readyHandler)
+
+	srv := &http.Server{Addr: net.JoinHostPort(host, port)}
+	if tlsEnabled() {
+		srv.TLSConfig = &tls.Config{GetCertificate: getCertificate}
+	}
+	go func() {
+		backoff := time.Second
+		for attempt := 1; ; attempt++ {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err == nil {
+				Readiness.Web.Store(true)
+				if srv.TLSConfig != nil {
+					log.Infof("Starting webserver with TLS on %s", srv.Addr)
+					err = srv.ServeTLS(ln, "", "")
+				} else {
+					log.Infof("Starting webserver on %s", srv.Addr)
+					err = srv.Serve(ln)
+				}
+				Readiness.Web.Store(false)
+			}
+			if err == nil || errors.Is(err, http.ErrServerClosed) {
+				return
+			}
+			if attempt == listenAttempts {
+				log.Fatalf("Webserver failed %d times, giving up: %v", attempt, err)
+			}
+			log.Errorf("Server stopped: %v, retrying in %s", err, backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}()
+	return srv
+}
+
+// Secret from an "Authorization: Bearer" or X-Secret header, falling
+// back to the deprecated ?secret= query param if AllowQuerySecret.
+func SecretFromRequest(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if token := r.Header.Get("X-Secret"); token != "" {
+		return token
+	}
+	if AllowQuerySecret {
+		if token := r.URL.Query().Get("secret"); token != "" {
+			querySecretWarning.Do(func() {
+				log.Warn("Secret passed as a query param, this is deprecated, send an X-Secret or Authorization header instead")
+			})
+			return token
+		}
+	}
+	return ""
+}
+
+// Secrets from a comma separated list, so a new one can be handed out
+// before the old one is dropped.
+func SplitSecrets(s string) []string {
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Whether r carries one of the secrets, fails closed when none are
+// configured. Every key is compared so timing doesn't give away which.
+func Authorized(r *http.Request) bool {
+	token := []byte(SecretFromRequest(r))
+	ok := false
+	for _, k := range secretKeys {
+		if subtle.ConstantTimeCompare(token, []byte(k)) == 1 {
+			ok = true
+		}
+	}
+	return ok
+}
+
+// Only lets requests carrying the secret through to h.
+func RequireSecret(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func recoverWrap(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Errorf("Recovered from panic: %v", rec)
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+		h(w, r)
+	}
+}
+
+// GET /messages, the queued messages as JSON including sender addresses,
+// paged with ?limit= and ?after= (see pageHandler). POST goes to
+// submitHandler.
+func listHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		submitHandler(w, r)
+		return
+	}
+	if !Authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if q := r.URL.Query(); q.Has("limit") || q.Has("after") {
+		pageHandler(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(store.Messages())
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	if !Authorized(r) {
+		log.Warn("Unauthorized message fetch", "remote", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// If a Worker is configured, use it
+	if store.HasWorker() {
+		body, workerErr := store.FetchFromWorker()
+		if !workerErr {
+			if body == "" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			if parts := strings.SplitN(body, "---", 3); len(parts) == 3 && utf8.RuneCountInString(parts[1]) > store.MaxMessageLength {
+				log.Warn("Dropping oversized message from worker", "from", parts[0], "length", utf8.RuneCountInString(parts[1]))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			log.Infof("Got message from worker: %s", body)
+			stats.MessagesFetched.Inc("worker")
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = fmt.Fprint(w, body)
+			return
+		}
+		log.Warn("Worker unavailable, falling back to in-memory store")
+	}
+
+	// In-memory fallback
+	if first, ok := store.TakeFirst(); ok {
+		log.Infof("Printing message %s", first.Content)
+		if utf8.RuneCountInString(first.Content) > store.MaxMessageLength {
+			log.Warn("Dropping oversized message", "from", first.From, "length", utf8.RuneCountInString(first.Content))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		stats.MessagesFetched.Inc("memory")
+		w.Header().Set("Content-Type", "text/plain")
+		// ?meta=1 adds the sender for printers that want it.
+		if r.URL.Query().Get("meta") == "1" {
+			_, _ = fmt.Fprintf(w, "%s---%s---%s---%s---%s", first.From, first.Content, first.Timestamp, first.RemoteAddr, first.Fingerprint)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "%s---%s---%s", first.From, first.Content, first.Timestamp)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,48 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Two messages alike in everything but their IDs and timestamps, e.g.
+// the same "hi" sent twice from two sessions with the same name.
+func queueTwins(t *testing.T) (store.Message, store.Message) {
+	t.Helper()
+	setQueue(t, store.RejectNew, 1000, 1<<20)
+	a, err := store.AddMessage("w", "hi", "192.0.2.1:1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := store.AddMessage("w", "hi", "192.0.2.1:1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+// The legacy fetch takes the oldest twin and leaves the other queued.
+func TestLatestTakesOldest(t *testing.T) {
+	useSecrets(t, "key")
+	a, b := queueTwins(t)
+	if w := fetchLatest(t, "/messages/latest", http.Header{"Authorization": {"Bearer key"}}); w.Code != http.StatusOK {
+		t.Fatalf("got %d", w.Code)
+	}
+	if got := store.QueuePosition(a); got != 0 {
+		t.Errorf("printed twin still queued at %d", got)
+	}
+	if got := store.QueuePosition(b); got != 1 {
+		t.Errorf("other twin at %d, want 1", got)
+	}
+	if w := fetchLatest(t, "/messages/latest", http.Header{"Authorization": {"Bearer key"}}); w.Code != http.StatusOK {
+		t.Fatalf("second fetch got %d", w.Code)
+	}
+	if w := fetchLatest(t, "/messages/latest", http.Header{"Authorization": {"Bearer key"}}); w.Code != http.StatusNoContent {
+		t.Errorf("empty queue got %d, want 204", w.Code)
+	}
+	if got := store.Queued(); got != 0 {
+		t.Errorf("queued %d, want 0", got)
+	}
+}
@@ -0,0 +1,18 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// GET /stats
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	if !Authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(store.Visitors())
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Lets POST /messages through without the secret, for a form on a
+// public site. Senders are still limited per IP.
+var AllowPublicPost = false
+
+type submission struct {
+	From    string `json:"from"`
+	Content string `json:"content"`
+}
+
+// POST /messages with {"from", "content"}, stored like a message from
+// the TUI. 201 with the message's id, 400 when it's invalid, 429 when
+// the sender's IP is over the limit.
+func submitHandler(w http.ResponseWriter, r *http.Request) {
+	if !AllowPublicPost && !Authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var sub submission
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&sub); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body must be JSON with from and content"})
+		return
+	}
+	from := strings.TrimSpace(sub.From)
+	if from == "" {
+		from = "anonymous"
+	}
+
+	msg, err := store.AddMessage(from, strings.TrimSpace(sub.Content), r.RemoteAddr, "")
+	switch {
+	case errors.Is(err, store.ErrRateLimited):
+		log.Warn("Rate limiting HTTP messages", "remote", r.RemoteAddr)
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	case errors.Is(err, store.ErrMailboxFull):
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusCreated, map[string]string{"id": msg.ID})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
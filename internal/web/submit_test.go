@@ -0,0 +1,105 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Empties the queue and turns off the rate limit and the duplicate
+// filter for a test, putting them back after. Whatever was queued is
+// dropped.
+func resetMessages(t *testing.T) {
+	t.Helper()
+	drain := func() {
+		for {
+			if _, ok := store.TakeFirst(); !ok {
+				return
+			}
+		}
+	}
+	drain()
+	savedRate, savedWindow := store.MessageRate, store.DuplicateWindow
+	store.MessageRate, store.DuplicateWindow = 0, 0
+	t.Cleanup(func() {
+		drain()
+		store.MessageRate, store.DuplicateWindow = savedRate, savedWindow
+	})
+}
+
+func setQueue(t *testing.T, policy string, maxMessages, maxBytes int) {
+	t.Helper()
+	resetMessages(t)
+	savedPolicy, savedMax, savedBytes := store.QueuePolicy, store.MaxQueue, store.MaxQueueBytes
+	store.QueuePolicy, store.MaxQueue, store.MaxQueueBytes = policy, maxMessages, maxBytes
+	t.Cleanup(func() {
+		store.QueuePolicy, store.MaxQueue, store.MaxQueueBytes = savedPolicy, savedMax, savedBytes
+	})
+}
+
+func fill(t *testing.T, n int) {
+	t.Helper()
+	for i := range n {
+		if _, err := store.AddMessage("w", fmt.Sprintf("message %d", i), "192.0.2.1:1", ""); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+	}
+}
+
+// The limiter can't be reset from here, so each run posts from its own
+// address and -count doesn't trip over the last one's.
+var submitRuns atomic.Int32
+
+func post(t *testing.T, addr, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(body))
+	r.RemoteAddr = addr
+	w := httptest.NewRecorder()
+	submitHandler(w, r)
+	return w
+}
+
+func TestSubmitHandler(t *testing.T) {
+	resetMessages(t)
+	AllowPublicPost = true
+	t.Cleanup(func() { AllowPublicPost = false })
+	addr := fmt.Sprintf("192.0.2.%d:1234", submitRuns.Add(1))
+
+	w := post(t, addr, `{"from":"will","content":"hello"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("valid message: got %d, want 201: %s", w.Code, w.Body)
+	}
+	var resp map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp["id"] == "" {
+		t.Fatalf("201 body = %v, %v; want an id", resp, err)
+	}
+	if got := store.Messages(); len(got) != 1 || got[0].ID != resp["id"] {
+		t.Fatalf("queue = %+v, want the one message", got)
+	}
+
+	for name, body := range map[string]string{
+		"not json":       `from=will`,
+		"empty":          `{"from":"will","content":"   "}`,
+		"too long":       `{"from":"will","content":"` + strings.Repeat("a", store.MaxMessageLength+1) + `"}`,
+		"long name":      `{"from":"` + strings.Repeat("w", store.MaxNameLength+1) + `","content":"hi"}`,
+		"escape":         `{"from":"will","content":"hi \u001b[2J"}`,
+		"escape in name": `{"from":"\u001b]0;pwned\u0007","content":"hi"}`,
+		"c1":             `{"from":"will","content":"hi \u009b2J"}`,
+	} {
+		if w := post(t, addr, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, w.Code)
+		}
+	}
+
+	store.MessageRate = 1
+	post(t, addr, `{"from":"will","content":"two"}`)
+	if w := post(t, addr, `{"from":"will","content":"three"}`); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("over the rate: got %d, want 429", w.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// The webserver's certificate, swapped by ReloadCertificate so a renewed
+// one is used without a restart.
+var (
+	certFile, keyFile string
+	cert              *tls.Certificate
+	certMu            sync.RWMutex
+)
+
+// Loads the pair the webserver serves HTTPS with. Both or neither have to
+// be set, with neither it stays on plain HTTP.
+func LoadCertificate(certPath, keyPath string) error {
+	if (certPath == "") != (keyPath == "") {
+		return errors.New("-tls-cert and -tls-key have to be set together")
+	}
+	certMu.Lock()
+	certFile, keyFile = certPath, keyPath
+	if certPath == "" {
+		cert = nil
+	}
+	certMu.Unlock()
+	if certPath == "" {
+		return nil
+	}
+	return ReloadCertificate()
+}
+
+// Reads the certificate again, e.g. after a Let's Encrypt renewal. The old
+// one is kept if the new pair doesn't load.
+func ReloadCertificate() error {
+	certMu.RLock()
+	c, k := certFile, keyFile
+	certMu.RUnlock()
+	if c == "" {
+		return nil
+	}
+	pair, err := tls.LoadX509KeyPair(c, k)
+	if err != nil {
+		return err
+	}
+	certMu.Lock()
+	cert = &pair
+	certMu.Unlock()
+	log.Info("Loaded TLS certificate", "cert", c)
+	return nil
+}
+
+func tlsEnabled() bool {
+	certMu.RLock()
+	defer certMu.RUnlock()
+	return cert != nil
+}
+
+func getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certMu.RLock()
+	defer certMu.RUnlock()
+	return cert, nil
+}
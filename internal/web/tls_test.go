@@ -0,0 +1,158 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Writes a self-signed pair for name to the same two files each time,
+// the way a renewal replaces them.
+func writePair(t *testing.T, dir, name string) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+func resetCertificate(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		certMu.Lock()
+		certFile, keyFile, cert = "", "", nil
+		certMu.Unlock()
+	})
+}
+
+// Serves TLS the way Start does, with only getCertificate to go on.
+func serveTLS(t *testing.T) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{GetCertificate: getCertificate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.NotFoundHandler(), ErrorLog: log.New(io.Discard, "", 0)}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+// The name on the certificate a new connection is handed.
+func servedName(t *testing.T, addr string) string {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertificateReload(t *testing.T) {
+	resetCertificate(t)
+	dir := t.TempDir()
+	certPath, keyPath := writePair(t, dir, "first.test")
+	if err := LoadCertificate(certPath, keyPath); err != nil {
+		t.Fatal(err)
+	}
+	if !tlsEnabled() {
+		t.Fatal("TLS not enabled after loading a pair")
+	}
+
+	srv := serveTLS(t)
+	if got := servedName(t, srv); got != "first.test" {
+		t.Fatalf("served %q, want first.test", got)
+	}
+
+	writePair(t, dir, "renewed.test")
+	if err := ReloadCertificate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := servedName(t, srv); got != "renewed.test" {
+		t.Errorf("after reload served %q, want renewed.test", got)
+	}
+
+	// A half-written renewal keeps the certificate that worked.
+	if err := os.WriteFile(certPath, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReloadCertificate(); err == nil {
+		t.Error("reloading a broken pair should fail")
+	}
+	if got := servedName(t, srv); got != "renewed.test" {
+		t.Errorf("after a failed reload served %q, want renewed.test", got)
+	}
+}
+
+func TestLoadCertificateErrors(t *testing.T) {
+	resetCertificate(t)
+	dir := t.TempDir()
+	certPath, keyPath := writePair(t, dir, "only.test")
+	for _, tt := range []struct {
+		name, cert, key string
+	}{
+		{"cert only", certPath, ""},
+		{"key only", "", keyPath},
+		{"missing files", filepath.Join(dir, "nope.pem"), filepath.Join(dir, "nope.key")},
+		{"swapped", keyPath, certPath},
+	} {
+		if err := LoadCertificate(tt.cert, tt.key); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+		if tlsEnabled() {
+			t.Errorf("%s: TLS enabled", tt.name)
+		}
+	}
+}
+
+func TestLoadCertificateNone(t *testing.T) {
+	resetCertificate(t)
+	if err := LoadCertificate(writePair(t, t.TempDir(), "old.test")); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCertificate("", ""); err != nil {
+		t.Fatal(err)
+	}
+	if tlsEnabled() {
+		t.Error("still on TLS with no pair set")
+	}
+	if err := ReloadCertificate(); err != nil || tlsEnabled() {
+		t.Errorf("reload with no pair: err %v, TLS %v", err, tlsEnabled())
+	}
+}
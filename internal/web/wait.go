@@ -0,0 +1,41 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// Longest a /messages/wait request is held open, clients can ask for
+// less with ?timeout=.
+var WaitTimeout = 30 * time.Second
+
+// GET /messages/wait, same response as /messages/latest but held open
+// until a message is added or the timeout passes, so printers needn't
+// poll. A Worker queue is only checked when the wait ends.
+func waitHandler(w http.ResponseWriter, r *http.Request) {
+	if !Authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	timeout := WaitTimeout
+	if d, err := time.ParseDuration(r.URL.Query().Get("timeout")); err == nil && d >= 0 && d < timeout {
+		timeout = d
+	}
+
+	// Taken before looking at the store so an add in between isn't missed.
+	ch := store.Arrival()
+	if len(store.Messages()) == 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		select {
+		case <-ch:
+		case <-t.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	handler(w, r)
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/will-x86/ssh-will-x86/internal/store"
+)
+
+// A /messages/wait server for a test, with WaitTimeout set to max.
+func waitServer(t *testing.T, max time.Duration) *httptest.Server {
+	t.Helper()
+	useSecrets(t, "key")
+	resetMessages(t)
+	saved := WaitTimeout
+	WaitTimeout = max
+	srv := httptest.NewServer(http.HandlerFunc(waitHandler))
+	t.Cleanup(func() {
+		srv.Close()
+		WaitTimeout = saved
+	})
+	return srv
+}
+
+type waitResult struct {
+	code int
+	body string
+	took time.Duration
+}
+
+func wait(t *testing.T, ctx context.Context, url, secret string) waitResult {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Error(err)
+		return waitResult{}
+	}
+	if secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return waitResult{took: time.Since(start)}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return waitResult{resp.StatusCode, string(body), time.Since(start)}
+}
+
+func TestWaitTimesOut(t *testing.T) {
+	srv := waitServer(t, 5*time.Second)
+	res := wait(t, context.Background(), srv.URL+"?timeout=50ms", "key")
+	if res.code != http.StatusNoContent {
+		t.Fatalf("empty queue: %d, want 204", res.code)
+	}
+	if res.took < 50*time.Millisecond || res.took > 2*time.Second {
+		t.Errorf("?timeout=50ms returned after %s", res.took)
+	}
+}
+
+func TestWaitTimeoutCapped(t *testing.T) {
+	srv := waitServer(t, 50*time.Millisecond)
+	for _, q := range []string{"", "?timeout=1h", "?timeout=-1s", "?timeout=soon"} {
+		res := wait(t, context.Background(), srv.URL+q, "key")
+		if res.code != http.StatusNoContent || res.took > 2*time.Second {
+			t.Errorf("%q: %d after %s, want 204 after WaitTimeout", q, res.code, res.took)
+		}
+	}
+}
+
+func TestWaitWakesOnMessage(t *testing.T) {
+	srv := waitServer(t, 5*time.Second)
+	done := make(chan waitResult, 1)
+	go func() { done <- wait(t, context.Background(), srv.URL, "key") }()
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := store.AddMessage("w", "wake up", "192.0.2.1:1", ""); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case res := <-done:
+		if res.code != http.StatusOK || !strings.HasPrefix(res.body, "w---wake up---") {
+			t.Errorf("woken: %d %q", res.code, res.body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("not woken by AddMessage")
+	}
+	if len(store.Messages()) != 0 {
+		t.Error("delivered message left queued")
+	}
+}
+
+func TestWaitMessageAlreadyQueued(t *testing.T) {
+	srv := waitServer(t, 5*time.Second)
+	if _, err := store.AddMessage("w", "already here", "192.0.2.1:1", ""); err != nil {
+		t.Fatal(err)
+	}
+	res := wait(t, context.Background(), srv.URL, "key")
+	if res.code != http.StatusOK || res.took > time.Second {
+		t.Errorf("queued message: %d after %s, want an immediate 200", res.code, res.took)
+	}
+}
+
+// Every waiter wakes, only one gets the message.
+func TestWaitOneMessageTwoWaiters(t *testing.T) {
+	srv := waitServer(t, 5*time.Second)
+	done := make(chan waitResult, 2)
+	for range 2 {
+		go func() { done <- wait(t, context.Background(), srv.URL, "key") }()
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := store.AddMessage("w", "just one", "192.0.2.1:1", ""); err != nil {
+		t.Fatal(err)
+	}
+	codes := map[int]int{}
+	for range 2 {
+		select {
+		case res := <-done:
+			codes[res.code]++
+		case <-time.After(2 * time.Second):
+			t.Fatal("a waiter wasn't woken")
+		}
+	}
+	if codes[http.StatusOK] != 1 || codes[http.StatusNoContent] != 1 {
+		t.Errorf("got %v, want one 200 and one 204", codes)
+	}
+}
+
+func TestWaitRejectsWithoutWaiting(t *testing.T) {
+	srv := waitServer(t, 5*time.Second)
+	res := wait(t, context.Background(), srv.URL, "guess")
+	if res.code != http.StatusUnauthorized || res.took > time.Second {
+		t.Errorf("wrong secret: %d after %s, want an immediate 401", res.code, res.took)
+	}
+}
+
+// A client that goes away frees the handler, and the message it didn't
+// wait for stays queued.
+func TestWaitClientGone(t *testing.T) {
+	srv := waitServer(t, 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if res := wait(t, ctx, srv.URL, "key"); res.code != 0 {
+		t.Fatalf("cancelled request got %d", res.code)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := store.AddMessage("w", "for later", "192.0.2.1:1", ""); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(store.Messages()) != 1 {
+		t.Error("gone client's handler took the message")
+	}
+}
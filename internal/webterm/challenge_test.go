@@ -0,0 +1,107 @@
+package webterm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestChallenger(t *testing.T) (*challenger, *time.Time) {
+	t.Helper()
+	c, err := newChallenger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func answer(t *testing.T, question string) string {
+	t.Helper()
+	var a, b int
+	if _, err := fmt.Sscanf(question, "What is %d + %d?", &a, &b); err != nil {
+		t.Fatalf("question %q: %v", question, err)
+	}
+	return fmt.Sprint(a + b)
+}
+
+func TestChallengeSingleUse(t *testing.T) {
+	c, _ := newTestChallenger(t)
+	q, token := c.issue()
+	if !c.verify(token, " "+answer(t, q)+" ") {
+		t.Fatal("right answer rejected")
+	}
+	if c.verify(token, answer(t, q)) {
+		t.Error("token replayed")
+	}
+
+	q, token = c.issue()
+	if c.verify(token, "99") {
+		t.Fatal("wrong answer accepted")
+	}
+	if c.verify(token, answer(t, q)) {
+		t.Error("second guess on a spent token accepted")
+	}
+}
+
+func TestChallengeRejects(t *testing.T) {
+	c, now := newTestChallenger(t)
+	q, token := c.issue()
+	for name, tok := range map[string]string{
+		"empty":       "",
+		"three parts": "a.b.c",
+		"tampered":    token[:len(token)-1] + "0",
+	} {
+		if c.verify(tok, answer(t, q)) {
+			t.Errorf("%s token accepted", name)
+		}
+	}
+	if c.verify(token, "") {
+		t.Error("empty answer accepted")
+	}
+
+	*now = now.Add(challengeTTL + time.Second)
+	if c.verify(token, answer(t, q)) {
+		t.Error("expired token accepted")
+	}
+
+	other, _ := newTestChallenger(t)
+	q, token = other.issue()
+	if c.verify(token, answer(t, q)) {
+		t.Error("token from another key accepted")
+	}
+}
+
+func TestChallengeSpentPrunedAfterExpiry(t *testing.T) {
+	c, now := newTestChallenger(t)
+	for range 1025 {
+		q, token := c.issue()
+		c.verify(token, answer(t, q))
+	}
+	q, kept := c.issue()
+	*now = now.Add(challengeTTL + time.Second)
+	// Issued after the clock moved, so still live while the rest prune.
+	q2, token := c.issue()
+	if !c.verify(token, answer(t, q2)) {
+		t.Fatal("fresh token rejected")
+	}
+	if len(c.spent) != 1 {
+		t.Errorf("%d spent tokens kept, want only the live one", len(c.spent))
+	}
+	if c.verify(kept, answer(t, q)) {
+		t.Error("expired token accepted after pruning")
+	}
+}
+
+func TestChallengeTokensDistinct(t *testing.T) {
+	c, _ := newTestChallenger(t)
+	seen := make(map[string]bool)
+	for range 100 {
+		_, token := c.issue()
+		if seen[token] {
+			t.Fatalf("token %q issued twice", token)
+		}
+		seen[token] = true
+	}
+}
@@ -0,0 +1,291 @@
+// Package webterm bridges a browser terminal (xterm.js over a WebSocket)
+// to an in-process SSH session against the regular TUI.
+package webterm
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"embed"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/net/websocket"
+)
+
+// Env var set on bridged sessions, the TUI reads it to lock things down.
+const (
+	EnvMode      = "WILLX86_WEB"
+	ModeReadOnly = "readonly" // browsing only, no messages
+	ModeVerified = "verified" // passed the challenge, messages allowed
+)
+
+const (
+	maxInputFrame = 4096
+	defaultCols   = 80
+	defaultRows   = 24
+)
+
+//go:embed assets
+var assets embed.FS
+
+var indexTmpl = template.Must(template.ParseFS(assets, "assets/index.html"))
+
+type Config struct {
+	MaxSessions int
+	MaxDuration time.Duration
+}
+
+type Bridge struct {
+	cfg       Config
+	srv       *ssh.Server
+	signer    gossh.Signer
+	slots     chan struct{}
+	challenge *challenger
+}
+
+// Browser -> server frames. Output goes the other way as raw binary frames.
+type frame struct {
+	Type string `json:"type"` // "data" or "resize"
+	Data string `json:"data,omitempty"`
+	Cols int    `json:"cols,omitempty"`
+	Rows int    `json:"rows,omitempty"`
+}
+
+// Creates a bridge with its own ephemeral key, the SSH server must
+// trust PublicKey() before Register is called.
+func NewBridge(cfg Config) (*Bridge, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := newChallenger()
+	if err != nil {
+		return nil, err
+	}
+	return &Bridge{
+		cfg:       cfg,
+		signer:    signer,
+		slots:     make(chan struct{}, cfg.MaxSessions),
+		challenge: challenge,
+	}, nil
+}
+
+func (b *Bridge) PublicKey() ssh.PublicKey {
+	return b.signer.PublicKey()
+}
+
+// Mounts /try on the default mux, sessions are served by srv.
+func (b *Bridge) Register(srv *ssh.Server) {
+	b.srv = srv
+	static, _ := fs.Sub(assets, "assets")
+	http.HandleFunc("/try", b.index)
+	http.Handle("/try/assets/", http.StripPrefix("/try/assets/", http.FileServer(http.FS(static))))
+	http.Handle("/try/ws", websocket.Server{Handler: b.serve, Handshake: sameOrigin})
+}
+
+func (b *Bridge) index(w http.ResponseWriter, r *http.Request) {
+	q, token := b.challenge.issue()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTmpl.Execute(w, map[string]any{
+		"Question":    q,
+		"Token":       token,
+		"MaxDuration": b.cfg.MaxDuration.String(),
+	}); err != nil {
+		log.Errorf("Webterm: rendering index failed: %v", err)
+	}
+}
+
+// Only accept sockets opened from our own page.
+func sameOrigin(cfg *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(cfg, r)
+	if err != nil {
+		return err
+	}
+	if origin == nil || origin.Host != r.Host {
+		return &websocket.ProtocolError{ErrorString: "cross origin websocket"}
+	}
+	cfg.Origin = origin
+	return nil
+}
+
+func (b *Bridge) serve(ws *websocket.Conn) {
+	defer ws.Close()
+	ws.PayloadType = websocket.BinaryFrame
+	ws.MaxPayloadBytes = maxInputFrame
+
+	select {
+	case b.slots <- struct{}{}:
+		defer func() { <-b.slots }()
+	default:
+		_, _ = io.WriteString(ws, "\r\nToo many browser sessions right now, try `ssh willx86.com` instead.\r\n")
+		return
+	}
+
+	r := ws.Request()
+	q := r.URL.Query()
+	mode := ModeReadOnly
+	if b.challenge.verify(q.Get("token"), q.Get("answer")) {
+		mode = ModeVerified
+	}
+	cols := intParam(q.Get("cols"), defaultCols)
+	rows := intParam(q.Get("rows"), defaultRows)
+
+	ctx, cancel := context.WithTimeout(r.Context(), b.cfg.MaxDuration)
+	defer cancel()
+
+	client, err := b.dial(r.RemoteAddr)
+	if err != nil {
+		log.Errorf("Webterm: in-process dial failed: %v", err)
+		return
+	}
+	defer client.Close()
+
+	sess, err := client.NewSession()
+	if err != nil {
+		log.Errorf("Webterm: opening session failed: %v", err)
+		return
+	}
+	defer sess.Close()
+
+	_ = sess.Setenv(EnvMode, mode)
+	if err := sess.RequestPty("xterm-256color", rows, cols, gossh.TerminalModes{}); err != nil {
+		log.Errorf("Webterm: pty request failed: %v", err)
+		return
+	}
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		return
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		return
+	}
+	if err := sess.Shell(); err != nil {
+		log.Errorf("Webterm: starting shell failed: %v", err)
+		return
+	}
+	log.Info("Webterm session started", "remote", r.RemoteAddr, "mode", mode, "cols", cols, "rows", rows)
+
+	// Output: blocking websocket writes stall the copy, which stops us
+	// reading the channel and lets the SSH window throttle the TUI.
+	go func() {
+		_, _ = io.Copy(ws, stdout)
+		cancel()
+	}()
+
+	// Input and resizes from the browser.
+	go func() {
+		defer cancel()
+		for {
+			var f frame
+			if err := websocket.JSON.Receive(ws, &f); err != nil {
+				return
+			}
+			switch f.Type {
+			case "data":
+				if _, err := io.WriteString(stdin, f.Data); err != nil {
+					return
+				}
+			case "resize":
+				if f.Cols > 0 && f.Rows > 0 {
+					_ = sess.WindowChange(f.Rows, f.Cols)
+				}
+			}
+		}
+	}()
+
+	<-ctx.Done()
+	if ctx.Err() == context.DeadlineExceeded {
+		_, _ = io.WriteString(ws, "\r\n\r\nSession time limit reached, thanks for visiting!\r\n")
+	}
+	log.Info("Webterm session ended", "remote", r.RemoteAddr)
+}
+
+// Runs the SSH handshake over a private loopback socket straight into
+// the server (net.Pipe would deadlock, both sides send their version
+// first). The server side reports the browser's address as the remote.
+func (b *Bridge) dial(remote string) (*gossh.Client, error) {
+	clientConn, serverConn, err := socketPair()
+	if err != nil {
+		return nil, err
+	}
+	go b.srv.HandleConn(browserConn{Conn: serverConn, remote: browserAddr(remote)})
+
+	conn, chans, reqs, err := gossh.NewClientConn(clientConn, "webterm", &gossh.ClientConfig{
+		User: "web",
+		Auth: []gossh.AuthMethod{gossh.PublicKeys(b.signer)},
+		// Host key can't be spoofed over our own socket pair.
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         10 * time.Second,
+	})
+	if err != nil {
+		_ = clientConn.Close()
+		return nil, err
+	}
+	return gossh.NewClient(conn, chans, reqs), nil
+}
+
+func socketPair() (net.Conn, net.Conn, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- c
+	}()
+
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		return nil, nil, err
+	}
+	server, ok := <-accepted
+	if !ok {
+		_ = client.Close()
+		return nil, nil, errors.New("webterm: accept failed")
+	}
+	return client, server, nil
+}
+
+type browserConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c browserConn) RemoteAddr() net.Addr { return c.remote }
+
+func browserAddr(remote string) net.Addr {
+	if addr, err := net.ResolveTCPAddr("tcp", remote); err == nil {
+		return addr
+	}
+	return &net.TCPAddr{IP: net.IPv4zero}
+}
+
+func intParam(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || n > 500 {
+		return def
+	}
+	return n
+}
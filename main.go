@@ -2,49 +2,368 @@ package main
 
 import (
 	"context"
+	"embed"
 	"errors"
 	"flag"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/ssh"
-	"github.com/will-x86/ssh-will-x86/pkg/server"
-	sshserver "github.com/will-x86/ssh-will-x86/pkg/ssh"
-	"github.com/will-x86/ssh-will-x86/pkg/ui"
+	"github.com/charmbracelet/wish"
+	"github.com/will-x86/ssh-will-x86/internal/diagnostics"
+	"github.com/will-x86/ssh-will-x86/internal/metrics"
+	sshserver "github.com/will-x86/ssh-will-x86/internal/ssh"
+	"github.com/will-x86/ssh-will-x86/internal/store"
+	"github.com/will-x86/ssh-will-x86/internal/tui"
+	"github.com/will-x86/ssh-will-x86/internal/web"
+	"github.com/will-x86/ssh-will-x86/internal/webterm"
 )
 
+// Set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// Baked in pages, -content-dir overrides them file by file.
+//
+//go:embed content/*.md
+var builtinContent embed.FS
+
 var (
 	hostFlag       = flag.String("host", "0.0.0.0", "Host to listen on")
 	portFlag       = flag.String("port", "22", "Port to listen on")
+	hostKeyDir     = flag.String("hostkey-dir", ".ssh", "Directory with the SSH host keys, an ed25519 key is generated if empty")
+	maxAuthFails   = flag.Int("max-auth-failures", 5, "Failed quiz answers from one IP before it's banned")
+	banDuration    = flag.Duration("ban-duration", 15*time.Minute, "How long an IP stays banned")
+	maxConnsPerIP  = flag.Int("max-conns-per-ip", 3, "Concurrent SSH connections allowed per IP")
+	connRate       = flag.Int("conn-rate", 10, "Sessions one IP can start per -conn-window (0 disables)")
+	connWindow     = flag.Duration("conn-window", time.Minute, "Window -conn-rate is counted over")
+	idleTimeout    = flag.Duration("idle-timeout", 10*time.Minute, "Disconnect sessions idle this long (0 disables)")
+	maxSession     = flag.Duration("max-session", 2*time.Hour, "Disconnect sessions after this long regardless (0 disables)")
+	webServerHost  = flag.String("webserver-host", "127.0.0.1", "Address the HTTP message server listens on, 0.0.0.0 (or empty) for every interface")
 	webServerPort  = flag.String("webserver-port", "9000", "Port for the HTTP message server")
-	secretKey      = flag.String("sK", os.Getenv("SECRET_KEY"), "Secret key for the message endpoint")
+	secretKey      = flag.String("sK", os.Getenv("SECRET_KEY"), "Secret key for the message endpoint, comma separated to accept several while rotating")
 	cfWorkerURL    = flag.String("cfWorkerURL", os.Getenv("CF_WORKER_URL"), "Cloudflare Worker URL for persistent message storage")
 	cfWorkerSecret = flag.String("cfWorkerSecret", os.Getenv("CF_WORKER_SECRET"), "Cloudflare Worker secret")
+	blocklistPath  = flag.String("blocklist", "blocklist.txt", "Patterns (substring, glob or /regex/) that quarantine a message, reloaded on SIGHUP")
+	dupWindow      = flag.Int("duplicate-window", 5, "Quarantine a message repeating one of the session's last this many (0 disables)")
+	notifyURL      = flag.String("notify-url", os.Getenv("NOTIFY_URL"), "Webhook (ntfy/Discord style) POSTed for every new message")
+	waitTimeout    = flag.Duration("wait-timeout", 30*time.Second, "Longest GET /messages/wait holds a request open for a new message")
+	publicPost     = flag.Bool("public-post", false, "Accept POST /messages without the secret, limited per IP like SSH messages")
+	messageRate    = flag.Int("message-rate", 5, "Messages one IP can send per -message-window over SSH and HTTP (0 disables)")
+	msgMax         = flag.Int("msg-max", 1000, "Most messages waiting for the printer (0 for no limit)")
+	msgMaxBytes    = flag.Int("msg-max-bytes", 1<<20, "Most bytes of messages waiting for the printer (0 for no limit)")
+	msgMaxAge      = flag.Duration("msg-max-age", 7*24*time.Hour, "Drop messages that have waited longer than this to print (0 keeps them)")
+	msgFull        = flag.String("msg-full", store.RejectNew, "What a full queue does with a new message: reject (the new one) or drop (the oldest)")
+	messageWindow  = flag.Duration("message-window", 10*time.Minute, "Window -message-rate is counted over")
+	querySecret    = flag.Bool("allow-query-secret", true, "Accept the secret as a ?secret= query param (deprecated, use the X-Secret header)")
+	corsOrigin     = flag.String("cors-origin", "", "Origin (or *) allowed to call the message endpoints from a browser, off if empty")
+	tlsCert        = flag.String("tls-cert", "", "TLS certificate for the webserver, reloaded on SIGHUP")
+	tlsKey         = flag.String("tls-key", "", "TLS private key for the webserver, needed with -tls-cert")
+	webTerminal    = flag.Bool("web-terminal", false, "Serve a browser terminal at /try on the webserver")
+	webTermMax     = flag.Int("web-terminal-sessions", 3, "Maximum concurrent browser terminal sessions")
+	webTermTimeout = flag.Duration("web-terminal-duration", 5*time.Minute, "Maximum length of a browser terminal session")
+	maxMessageLen  = flag.Int("max-message-length", 500, "Longest message in characters visitors can send")
+	receiptWidth   = flag.Int("receipt-width", 32, "Characters per line on the printer, for the message preview")
+	metricsSecret  = flag.Bool("metrics-secret", false, "Require the secret for /metrics, on the webserver or -metrics-port")
+	metricsPort    = flag.String("metrics-port", "", "Serve /metrics on its own port of -webserver-host instead of the webserver")
+	githubUser     = flag.String("github-user", "", "Merge this GitHub user's top repositories into the projects list")
+	githubToken    = flag.String("github-token", os.Getenv("GITHUB_TOKEN"), "GitHub token for a higher API rate limit")
+	githubCache    = flag.String("github-cache", ".cache/github-projects.json", "Where fetched GitHub projects are cached")
+	githubTTL      = flag.Duration("github-ttl", 6*time.Hour, "How long cached GitHub projects are used before refetching")
+	contentDir     = flag.String("content-dir", "content", "Directory with home.md, blog.md and contact.md, built in copies are used for missing ones. Reloaded on SIGHUP")
+	vimInput       = flag.Bool("vim-input", false, "Vim style normal/insert modes in the message editor")
+	showBanner     = flag.Bool("banner", true, "Show the big ASCII banner on the home screen")
+	guestbook      = flag.Bool("guestbook", false, "Let visitors make messages public in a guestbook on 'w', once approved")
+	guestbookFile  = flag.String("guestbook-file", "guestbook.json", "Where public guestbook messages are kept")
+	defaultTheme   = flag.String("default-theme", "auto", "Theme sessions start with: auto (by terminal background), default, gruvbox, nord, dracula, solarized or mono")
+	prefsFile      = flag.String("prefs-file", "prefs.json", "Where name, theme and section are remembered per visitor key")
+	analyticsFile  = flag.String("analytics-file", "analytics.json", "Where visitor counts are saved so they survive restarts")
+	noAuth         = flag.Bool("no-auth", false, "Let everyone in without the quiz, for demos only")
+	resumePDF      = flag.String("resume-pdf", "resume.pdf", "PDF served as resume.pdf over scp")
+	authQuestion   = flag.String("auth-question", sshserver.DefaultQuiz.Question, "Question visitors answer to log in")
+	authHint       = flag.String("auth-hint", sshserver.DefaultQuiz.Hint, "Hint shown with the question")
+	authAnswers    = flag.String("auth-answers", strings.Join(sshserver.DefaultQuiz.Answers, ","), "Comma separated answers that let visitors in")
+	authCase       = flag.Bool("auth-case-sensitive", false, "Compare answers case sensitively")
+	questionsPath  = flag.String("questions", "questions.txt", "Pool of \"question | answer, answer\" lines, 1-2 are asked at random instead of -auth-question")
+	quizRetries    = flag.Int("quiz-retries", 2, "Wrong answers allowed per connection before it's refused")
+	authKeysPath   = flag.String("authorized-keys", "authorized_keys", "Visitors' public keys that skip the quiz, messages are signed with the key comment")
+	adminUser      = flag.String("admin-user", "", "Username that gets the message inbox when logging in with a key from -admin-keys")
+	adminKeysPath  = flag.String("admin-keys", "admin_keys", "authorized_keys style file with the owner's public keys")
+	recordDir      = flag.String("record-dir", "", "Record every session as an asciinema cast in this directory, for debugging rendering")
+	recordLimit    = flag.Int64("record-limit", 10<<20, "Bytes a session recording can grow to before it stops (0 for no cap)")
+	watchProjects  = flag.Bool("watch", false, "Reload projects.txt when it changes")
+	logFormat      = flag.String("log-format", "text", "Log output: text, json or logfmt")
+	logLevel       = flag.String("log-level", "info", "Least severe level logged: debug, info, warn or error")
+	diagnosticsOut = flag.String("diagnostics", "", "Fetch a diagnostic bundle from the running server into this .tar.gz and exit")
+	dailyTZ        = flag.String("daily-tz", "UTC", "Timezone the daily featured project/quote flips in")
+	dailySalt      = flag.String("daily-salt", os.Getenv("DAILY_SALT"), "Salt for the daily content rotation")
 )
 
 func main() {
 	flag.Parse()
-	if *secretKey == "" {
+	configureLogging()
+	secrets := web.SplitSecrets(*secretKey)
+	if len(secrets) == 0 {
 		panic("no key set")
 	}
+	if *diagnosticsOut != "" {
+		scheme := "http"
+		if *tlsCert != "" && *tlsKey != "" {
+			scheme = "https"
+		}
+		host := *webServerHost
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		if err := diagnostics.Fetch(scheme+"://"+net.JoinHostPort(host, *webServerPort), secrets[0], *diagnosticsOut); err != nil {
+			log.Error("Could not fetch diagnostics", "error", err)
+			os.Exit(1)
+		}
+		log.Info("Wrote diagnostics bundle", "path", *diagnosticsOut)
+		return
+	}
+
+	logs := diagnostics.NewRing(2000)
+	log.SetOutput(io.MultiWriter(os.Stderr, logs))
+
+	loc, err := time.LoadLocation(*dailyTZ)
+	if err != nil {
+		log.Error("Invalid daily timezone", "tz", *dailyTZ, "error", err)
+		os.Exit(1)
+	}
+	store.ConfigureDaily(loc, *dailySalt)
+	store.ConfigureGitHub(store.GitHubConfig{
+		User:      *githubUser,
+		Token:     *githubToken,
+		CachePath: *githubCache,
+		TTL:       *githubTTL,
+	})
+	tui.DefaultPages, _ = fs.Sub(builtinContent, "content")
+	if err := tui.LoadPages(*contentDir); err != nil {
+		log.Error("Could not load pages", "error", err)
+		os.Exit(1)
+	}
+	if err := tui.SetDefaultTheme(*defaultTheme); err != nil {
+		log.Error("Bad -default-theme", "error", err)
+		os.Exit(1)
+	}
+	tui.ShowBanner = *showBanner
+	tui.VimInput = *vimInput
+	tui.GuestbookEnabled = *guestbook
+	tui.ReceiptWidth = *receiptWidth
+	tui.IdleTimeout = *idleTimeout
+	tui.MaxSessionDuration = *maxSession
+	store.MaxMessageLength = *maxMessageLen
+	web.AllowQuerySecret = *querySecret
+	web.WaitTimeout = *waitTimeout
+	web.AllowPublicPost = *publicPost
+	store.MessageRate = *messageRate
+	store.DuplicateWindow = *dupWindow
+	store.MaxQueue = *msgMax
+	store.MaxQueueBytes = *msgMaxBytes
+	store.MaxMessageAge = *msgMaxAge
+	web.CORSOrigin = *corsOrigin
+	if *msgFull != store.DropOldest && *msgFull != store.RejectNew {
+		log.Error("Unknown -msg-full, want drop or reject", "policy", *msgFull)
+		os.Exit(1)
+	}
+	store.QueuePolicy = *msgFull
+	store.MessageWindow = *messageWindow
+	store.Version = version
+	if err := store.LoadBlocklist(*blocklistPath); err != nil {
+		log.Error("Could not load blocklist", "error", err)
+		os.Exit(1)
+	}
+	store.StartJanitor(time.Minute)
+	if err := store.StartAnalytics(*analyticsFile, time.Minute); err != nil {
+		log.Error("Could not load analytics", "error", err)
+		os.Exit(1)
+	}
+	if err := store.LoadPrefs(*prefsFile); err != nil {
+		log.Error("Could not load preferences", "error", err)
+		os.Exit(1)
+	}
+	if *guestbook {
+		if err := store.LoadGuestbook(*guestbookFile); err != nil {
+			log.Error("Could not load guestbook", "error", err)
+			os.Exit(1)
+		}
+	}
+	if *notifyURL != "" {
+		store.StartNotifier(*notifyURL)
+	}
+
+	var bridge *webterm.Bridge
+	var trusted []ssh.PublicKey
+	if *webTerminal {
+		bridge, err = webterm.NewBridge(webterm.Config{MaxSessions: *webTermMax, MaxDuration: *webTermTimeout})
+		if err != nil {
+			log.Error("Could not create web terminal", "error", err)
+			os.Exit(1)
+		}
+		trusted = append(trusted, bridge.PublicKey())
+	}
+
+	if *adminUser != "" {
+		keys, err := sshserver.LoadAuthorizedKeys(*adminKeysPath)
+		if err != nil {
+			log.Error("Could not load admin keys", "error", err)
+			os.Exit(1)
+		}
+		if len(keys) == 0 {
+			log.Warn("No admin keys, the inbox can't be opened", "path", *adminKeysPath)
+		}
+		tui.AdminUser = *adminUser
+		for _, k := range keys {
+			tui.AdminKeys = append(tui.AdminKeys, k.Key)
+			trusted = append(trusted, k.Key)
+		}
+	}
+	authorized, err := sshserver.LoadAuthorizedKeys(*authKeysPath)
+	if err != nil {
+		log.Error("Could not load authorized keys", "error", err)
+		os.Exit(1)
+	}
 
-	go server.WebServer(*webServerPort, *secretKey, *cfWorkerURL, *cfWorkerSecret)
+	questions, err := sshserver.LoadQuestions(*questionsPath)
+	if err != nil {
+		log.Error("Could not load questions", "error", err)
+		os.Exit(1)
+	}
 
-	srv, err := sshserver.NewServer(*hostFlag, *portFlag, ui.NewTeaHandler())
+	if *noAuth {
+		log.Warn("!!! AUTH IS DISABLED (-no-auth), anyone can connect without the quiz. Don't run this in production !!!")
+	}
+
+	registry := metrics.NewRegistry()
+	stats := metrics.New(registry)
+	store.UseMetrics(stats)
+	web.UseMetrics(stats)
+
+	srv, err := sshserver.NewServer(sshserver.Config{
+		Host:       *hostFlag,
+		Port:       *portFlag,
+		Handler:    tui.NewTeaHandler(),
+		Commands:   tui.Commands(),
+		Downloads:  tui.Downloads(*resumePDF),
+		HostKeyDir: *hostKeyDir,
+		Gate: sshserver.GateConfig{
+			MaxFailures:   *maxAuthFails,
+			FailureWindow: 10 * time.Minute,
+			BanDuration:   *banDuration,
+			MaxConnsPerIP: *maxConnsPerIP,
+			ConnRate:      *connRate,
+			ConnWindow:    *connWindow,
+		},
+		Quiz: sshserver.Quiz{
+			Question:      *authQuestion,
+			Hint:          *authHint,
+			Answers:       strings.Split(*authAnswers, ","),
+			CaseSensitive: *authCase,
+		},
+		Questions:      questions,
+		QuizRetries:    *quizRetries,
+		NoAuth:         *noAuth,
+		TrustedKeys:    trusted,
+		AuthorizedKeys: authorized,
+		AuthObservers: []func(sshserver.AuthAttempt, sshserver.Decision){
+			func(a sshserver.AuthAttempt, d sshserver.Decision) {
+				if d == sshserver.Deny {
+					stats.AuthFailures.Inc(a.Method)
+				}
+			},
+		},
+		RecordDir:   *recordDir,
+		RecordLimit: *recordLimit,
+		Middleware:  []wish.Middleware{stats.Middleware()},
+	})
 	if err != nil {
 		log.Error("Could not create SSH server", "error", err)
 		os.Exit(1)
 	}
+	if bridge != nil {
+		bridge.Register(srv)
+	}
+
+	// The secret is required on whichever listener serves /metrics, its
+	// own port binds to the webserver's host rather than every interface.
+	metricsHandler := registry.Handler()
+	if *metricsSecret {
+		metricsHandler = web.RequireSecret(metricsHandler)
+	}
+	var metricsSrv *http.Server
+	if *metricsPort != "" {
+		metricsSrv = &http.Server{Addr: net.JoinHostPort(*webServerHost, *metricsPort), Handler: metricsHandler}
+		go func() {
+			log.Infof("Starting metrics server on %s", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("Metrics server stopped: %v", err)
+			}
+		}()
+	} else {
+		http.Handle("/metrics", metricsHandler)
+	}
+	http.HandleFunc("/diagnostics", diagnostics.Handler(diagnostics.Sources{
+		Flags:    flag.CommandLine,
+		Logs:     logs,
+		Registry: registry,
+		Metrics:  stats,
+	}))
+
+	if err := web.LoadCertificate(*tlsCert, *tlsKey); err != nil {
+		log.Error("Could not load TLS certificate", "error", err)
+		os.Exit(1)
+	}
+	webSrv := web.Start(*webServerHost, *webServerPort, *secretKey, *cfWorkerURL, *cfWorkerSecret)
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	if err := store.Reload(); err != nil {
+		log.Error("Could not load projects", "error", err)
+	}
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	if *watchProjects {
+		if err := store.Watch(stopWatch); err != nil {
+			log.Error("Could not watch projects", "error", err)
+		}
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			log.Info("SIGHUP, reloading projects, pages, blocklist and TLS certificate")
+			_ = store.Reload()
+			if err := tui.LoadPages(*contentDir); err != nil {
+				log.Error("Could not reload pages, keeping previous", "error", err)
+			}
+			if err := store.LoadBlocklist(*blocklistPath); err != nil {
+				log.Error("Could not reload blocklist, keeping previous", "error", err)
+			}
+			if err := web.ReloadCertificate(); err != nil {
+				log.Error("Could not reload TLS certificate, keeping previous", "error", err)
+			}
+		}
+	}()
+
 	go func() {
 		log.Info("Starting SSH server", "host", *hostFlag, "port", *portFlag)
-		if err = srv.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		ln, err := net.Listen("tcp", srv.Addr)
+		if err != nil {
+			log.Error("Could not start server", "error", err)
+			done <- nil
+			return
+		}
+		web.Readiness.SSH.Store(true)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 			log.Error("Could not start server", "error", err)
 			done <- nil
 		}
@@ -58,4 +377,38 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 		log.Error("Could not stop server", "error", err)
 	}
+	store.SaveAnalytics()
+	store.SavePrefs()
+	store.SaveGuestbook()
+	log.Info("Stopping webserver")
+	if err := webSrv.Shutdown(ctx); err != nil {
+		log.Error("Could not stop webserver", "error", err)
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			log.Error("Could not stop metrics server", "error", err)
+		}
+	}
+}
+
+// Set up before anything logs so startup lines use the chosen format too.
+func configureLogging() {
+	switch *logFormat {
+	case "text":
+	case "json":
+		log.SetFormatter(log.JSONFormatter)
+		log.SetTimeFormat(time.RFC3339)
+	case "logfmt":
+		log.SetFormatter(log.LogfmtFormatter)
+		log.SetTimeFormat(time.RFC3339)
+	default:
+		log.Error("Unknown -log-format, want text, json or logfmt", "format", *logFormat)
+		os.Exit(1)
+	}
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		log.Error("Bad -log-level", "error", err)
+		os.Exit(1)
+	}
+	log.SetLevel(level)
 }